@@ -0,0 +1,327 @@
+package genmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const adminPath = "/__admin"
+
+type adminRoute struct {
+	Method        string             `json:"method"`
+	Path          string             `json:"path"`
+	OperationId   string             `json:"operationId,omitempty"`
+	DbEntry       string             `json:"dbEntry"`
+	ResponseCode  string             `json:"responseCode"`
+	ResponseCodes []string           `json:"responseCodes,omitempty"`
+	Chaos         *Chaos             `json:"chaos,omitempty"`
+	Validation    *RequestValidation `json:"validation,omitempty"`
+}
+
+type routeOverride struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Status  int               `json:"status,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Times   int               `json:"times,omitempty"`
+}
+
+func adminRoutes(featureFileDataStructure map[string]map[string][]RequestStructure) []adminRoute {
+	routes := []adminRoute{}
+	routeMap := map[string]bool{}
+	for _, method := range slices.Sorted(maps.Keys(featureFileDataStructure)) {
+		calls := featureFileDataStructure[method]
+		for _, callPath := range sortedRoutePaths(calls) {
+			for _, filterPath := range calls[callPath] {
+				path := strings.Split(filterPath.Path, "?")[0]
+				routeKey := fmt.Sprintf("%s %s", strings.ToUpper(filterPath.Method), path)
+				if routeMap[routeKey] {
+					continue
+				}
+				routeMap[routeKey] = true
+				var responseCodes []string
+				if len(filterPath.Responses) > 0 {
+					responseCodes = slices.Sorted(maps.Keys(filterPath.Responses))
+				}
+				routes = append(routes, adminRoute{
+					Method:        strings.ToUpper(filterPath.Method),
+					Path:          path,
+					OperationId:   filterPath.OperationId,
+					DbEntry:       filterPath.DbEntry,
+					ResponseCode:  filterPath.ResponseCode,
+					ResponseCodes: responseCodes,
+					Chaos:         filterPath.Chaos,
+					Validation:    filterPath.Validation,
+				})
+			}
+		}
+	}
+
+	return routes
+}
+
+func isAdminPath(path string) bool {
+	return path == adminPath || strings.HasPrefix(path, adminPath+"/")
+}
+
+func (s *Server) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	switch strings.TrimPrefix(r.URL.Path, adminPath) {
+	case "/chaos":
+		s.serveAdminChaos(w, r)
+	case "/routes":
+		s.serveAdminRoutes(w, r)
+	case "/overrides":
+		s.serveAdminOverrides(w, r)
+	case "/reset":
+		s.serveAdminReset(w, r)
+	case "/scenarios":
+		s.serveAdminScenarios(w, r)
+	case "/requests":
+		s.serveAdminRequests(w, r)
+	case "/requests/find", "/requests/count", "/requests/verify":
+		s.serveAdminRequestsMatch(w, r)
+	case "/requests/export":
+		s.serveAdminRequestsExport(w, r)
+	default:
+		writeJson(w, http.StatusNotFound, map[string]any{})
+	}
+}
+
+func (s *Server) serveAdminChaos(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		chaos := s.Chaos
+		s.mu.Unlock()
+		writeJson(w, http.StatusOK, chaos)
+	case http.MethodPut:
+		chaos := &Chaos{}
+		err := json.NewDecoder(r.Body).Decode(chaos)
+		if err == nil {
+			err = chaos.check()
+		}
+		if err != nil {
+			writeJson(w, http.StatusBadRequest, map[string]any{"message": err.Error()})
+			return
+		}
+		s.mu.Lock()
+		s.Chaos = chaos
+		s.mu.Unlock()
+		writeJson(w, http.StatusOK, chaos)
+	case http.MethodDelete:
+		s.mu.Lock()
+		s.Chaos = nil
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJson(w, http.StatusMethodNotAllowed, map[string]any{})
+	}
+}
+
+func (s *Server) serveAdminRoutes(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJson(w, http.StatusMethodNotAllowed, map[string]any{})
+		return
+	}
+	writeJson(w, http.StatusOK, s.adminRoutes)
+}
+
+func (s *Server) serveAdminOverrides(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		overrides := append([]routeOverride{}, s.overrides...)
+		s.mu.Unlock()
+		writeJson(w, http.StatusOK, overrides)
+	case http.MethodPost:
+		override := routeOverride{}
+		err := json.NewDecoder(r.Body).Decode(&override)
+		if err == nil {
+			err = s.checkOverride(&override)
+		}
+		if err != nil {
+			writeJson(w, http.StatusBadRequest, map[string]any{"message": err.Error()})
+			return
+		}
+		s.mu.Lock()
+		s.overrides = append(s.overrides, override)
+		s.mu.Unlock()
+		writeJson(w, http.StatusCreated, override)
+	case http.MethodDelete:
+		s.mu.Lock()
+		s.overrides = nil
+		s.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJson(w, http.StatusMethodNotAllowed, map[string]any{})
+	}
+}
+
+func (s *Server) serveAdminReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJson(w, http.StatusMethodNotAllowed, map[string]any{})
+		return
+	}
+	s.mu.Lock()
+	s.db = cloneCollections(s.initialDb)
+	s.resetScenarios()
+	s.mu.Unlock()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) serveAdminScenarios(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJson(w, http.StatusOK, s.ScenarioStates())
+	case http.MethodPut:
+		states := map[string]string{}
+		err := json.NewDecoder(r.Body).Decode(&states)
+		if err == nil {
+			err = s.SetScenarioStates(states)
+		}
+		if err != nil {
+			writeJson(w, http.StatusBadRequest, map[string]any{"message": err.Error()})
+			return
+		}
+		writeJson(w, http.StatusOK, s.ScenarioStates())
+	default:
+		writeJson(w, http.StatusMethodNotAllowed, map[string]any{})
+	}
+}
+
+func (s *Server) serveAdminRequests(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		query := r.URL.Query()
+		writeJson(w, http.StatusOK, s.FindRequests(RequestMatcher{
+			Method:      query.Get("method"),
+			Path:        query.Get("path"),
+			OperationId: query.Get("operationId"),
+		}))
+	case http.MethodDelete:
+		s.ClearRequests()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeJson(w, http.StatusMethodNotAllowed, map[string]any{})
+	}
+}
+
+func (s *Server) serveAdminRequestsMatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJson(w, http.StatusMethodNotAllowed, map[string]any{})
+		return
+	}
+	verification := struct {
+		RequestMatcher
+		Count *int `json:"count"`
+	}{}
+	if err := json.NewDecoder(r.Body).Decode(&verification); err != nil {
+		writeJson(w, http.StatusBadRequest, map[string]any{"message": err.Error()})
+		return
+	}
+	requests := s.FindRequests(verification.RequestMatcher)
+	switch strings.TrimPrefix(r.URL.Path, adminPath+"/requests/") {
+	case "find":
+		writeJson(w, http.StatusOK, requests)
+	case "count":
+		writeJson(w, http.StatusOK, map[string]any{"count": len(requests)})
+	case "verify":
+		message := ""
+		if verification.Count == nil && len(requests) == 0 {
+			message = fmt.Sprintf("expected at least one request matching %s, got 0", verification.RequestMatcher)
+		}
+		if verification.Count != nil {
+			if err := verifyCount(verification.RequestMatcher, *verification.Count, len(requests)); err != nil {
+				message = err.Error()
+			}
+		}
+		if message != "" {
+			writeJson(w, http.StatusUnprocessableEntity, map[string]any{"message": message, "count": len(requests)})
+			return
+		}
+		writeJson(w, http.StatusOK, map[string]any{"count": len(requests)})
+	}
+}
+
+func (s *Server) serveAdminRequestsExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJson(w, http.StatusMethodNotAllowed, map[string]any{})
+		return
+	}
+	content, err := s.ExportRequests(r.URL.Query().Get("format"))
+	if err != nil {
+		writeJson(w, http.StatusBadRequest, map[string]any{"message": err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(content)
+}
+
+func (s *Server) checkOverride(override *routeOverride) error {
+	override.Method = strings.ToUpper(override.Method)
+	override.Path = pathParamPattern.ReplaceAllString(override.Path, ":$1")
+	if !slices.ContainsFunc(s.adminRoutes, func(route adminRoute) bool {
+		return route.Method == override.Method && route.Path == override.Path
+	}) {
+		return fmt.Errorf("unknown route '%s %s', see %s/routes for the mocked routes", override.Method, override.Path, adminPath)
+	}
+	if override.Status != 0 && (override.Status < 100 || override.Status > 599) {
+		return fmt.Errorf("invalid status %d", override.Status)
+	}
+	if override.Times < 0 {
+		return fmt.Errorf("invalid times %d, use a positive number of calls", override.Times)
+	}
+
+	return nil
+}
+
+func (s *Server) takeOverride(matchedRoute *route) (routeOverride, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	method := strings.ToUpper(matchedRoute.method)
+	path := strings.Split(matchedRoute.request.Path, "?")[0]
+	for i, override := range s.overrides {
+		if override.Method != method || override.Path != path {
+			continue
+		}
+		if override.Times > 0 {
+			s.overrides[i].Times--
+			if s.overrides[i].Times == 0 {
+				s.overrides = slices.Delete(s.overrides, i, i+1)
+			}
+		}
+		return override, true
+	}
+
+	return routeOverride{}, false
+}
+
+func writeOverride(w http.ResponseWriter, matchedRoute *route, override routeOverride) {
+	statusCode := override.Status
+	if statusCode == 0 {
+		statusCode, _ = strconv.Atoi(matchedRoute.request.ResponseCode)
+		statusCode = max(statusCode, http.StatusOK)
+	}
+	setHeaders(w, override.Headers)
+	if override.Body == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+	writeJson(w, statusCode, override.Body)
+}
+
+func cloneCollections(collections map[string][]any) map[string][]any {
+	clone := map[string][]any{}
+	for collection, records := range collections {
+		clone[collection] = cloneValue(records).([]any)
+	}
+
+	return clone
+}
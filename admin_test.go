@@ -0,0 +1,201 @@
+package genmock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func Test_Server_ServeHTTP_AdminRoutes(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplestatuses.yaml", GenerateOptions{MaxRecursionDepth: 1, AllResponses: true})
+	require.NoError(t, err)
+	server := NewServer(featureFileDataStructure)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/__admin/routes", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.JSONEq(t, `[
+		{"method":"DELETE","path":"/pets/:petId","dbEntry":"pets","responseCode":"204","responseCodes":["204","409"]},
+		{"method":"GET","path":"/pets/:petId","dbEntry":"pets","responseCode":"200","responseCodes":["200","404","5XX","default"]}
+	]`, recorder.Body.String())
+}
+
+func Test_Server_ServeHTTP_AdminOverrides(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		override      string
+		calls         int
+		expectedCodes []int
+		expectedBody  string
+		expectedError string
+	}{
+		"next calls": {
+			override:      `{"method": "get", "path": "/pets/{petId}", "status": 503, "body": {"message": "down"}, "headers": {"Retry-After": "5"}, "times": 2}`,
+			calls:         3,
+			expectedCodes: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusOK},
+			expectedBody:  `{"message":"down"}`,
+		},
+		"until removed": {
+			override:      `{"method": "GET", "path": "/pets/:petId", "body": []}`,
+			calls:         3,
+			expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+			expectedBody:  `[]`,
+		},
+		"status only": {
+			override:      `{"method": "GET", "path": "/pets/:petId", "status": 204, "times": 1}`,
+			calls:         1,
+			expectedCodes: []int{http.StatusNoContent},
+		},
+		"unknown route": {
+			override:      `{"method": "GET", "path": "/owners"}`,
+			expectedError: `{"message":"unknown route 'GET /owners', see /__admin/routes for the mocked routes"}`,
+		},
+		"invalid status": {
+			override:      `{"method": "GET", "path": "/pets/:petId", "status": 1000}`,
+			expectedError: `{"message":"invalid status 1000"}`,
+		},
+		"invalid times": {
+			override:      `{"method": "GET", "path": "/pets/:petId", "times": -1}`,
+			expectedError: `{"message":"invalid times -1, use a positive number of calls"}`,
+		},
+	}
+
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplestatuses.yaml", GenerateOptions{MaxRecursionDepth: 1})
+	require.NoError(t, err)
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := NewServer(featureFileDataStructure)
+			created := httptest.NewRecorder()
+
+			// Act
+			server.ServeHTTP(created, httptest.NewRequest(http.MethodPost, "/__admin/overrides", strings.NewReader(data.override)))
+			codes := []int{}
+			var last *httptest.ResponseRecorder
+			for range data.calls {
+				last = httptest.NewRecorder()
+				server.ServeHTTP(last, httptest.NewRequest(http.MethodGet, "/pets/1", nil))
+				codes = append(codes, last.Code)
+				if len(codes) == 1 && data.expectedBody != "" {
+					assert.JSONEq(t, data.expectedBody, last.Body.String())
+				}
+			}
+
+			// Assert
+			if data.expectedError != "" {
+				assert.Equal(t, http.StatusBadRequest, created.Code)
+				assert.JSONEq(t, data.expectedError, created.Body.String())
+				return
+			}
+			assert.Equal(t, http.StatusCreated, created.Code)
+			assert.Equal(t, data.expectedCodes, codes)
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_AdminOverridesHeaders(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplestatuses.yaml", GenerateOptions{MaxRecursionDepth: 1})
+	require.NoError(t, err)
+	server := NewServer(featureFileDataStructure)
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/__admin/overrides", strings.NewReader(`{"method": "DELETE", "path": "/pets/:petId", "status": 429, "headers": {"Retry-After": "5"}, "times": 1}`)))
+	listed := httptest.NewRecorder()
+	server.ServeHTTP(listed, httptest.NewRequest(http.MethodGet, "/__admin/overrides", nil))
+	overridden := httptest.NewRecorder()
+	cleared := httptest.NewRecorder()
+
+	// Act
+	server.ServeHTTP(overridden, httptest.NewRequest(http.MethodDelete, "/pets/1", nil))
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/__admin/overrides", nil))
+	server.ServeHTTP(cleared, httptest.NewRequest(http.MethodGet, "/__admin/overrides", nil))
+
+	// Assert
+	assert.JSONEq(t, `[{"method":"DELETE","path":"/pets/:petId","status":429,"headers":{"Retry-After":"5"},"times":1}]`, listed.Body.String())
+	assert.Equal(t, http.StatusTooManyRequests, overridden.Code)
+	assert.Equal(t, "5", overridden.Header().Get("Retry-After"))
+	assert.Empty(t, overridden.Body.String())
+	assert.JSONEq(t, `[]`, cleared.Body.String())
+}
+
+func Test_Server_ServeHTTP_AdminReset(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/exampledbrecords.yaml", GenerateOptions{MaxRecursionDepth: 1, GenExamples: true, DbRecords: 2, Seed: 1})
+	require.NoError(t, err)
+	server := NewServer(featureFileDataStructure)
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"name":"Rex"}`)))
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPatch, "/pets/1", strings.NewReader(`{"name":"Max"}`)))
+	reset := httptest.NewRecorder()
+	recorder := httptest.NewRecorder()
+
+	// Act
+	server.ServeHTTP(reset, httptest.NewRequest(http.MethodPost, "/__admin/reset", nil))
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/pets", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusNoContent, reset.Code)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.NotContains(t, recorder.Body.String(), "Rex")
+	assert.NotContains(t, recorder.Body.String(), "Max")
+	assert.Equal(t, 2, strings.Count(recorder.Body.String(), `"petId"`))
+}
+
+func Test_Server_ServeHTTP_AdminRequests(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplestatuses.yaml", GenerateOptions{MaxRecursionDepth: 1})
+	require.NoError(t, err)
+	server := NewServer(featureFileDataStructure)
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/owners?dryRun=true", strings.NewReader(`{"name":"Rex"}`)))
+	journal := httptest.NewRecorder()
+	cleared := httptest.NewRecorder()
+
+	// Act
+	server.ServeHTTP(journal, httptest.NewRequest(http.MethodGet, "/__admin/requests", nil))
+	server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/__admin/requests", nil))
+	server.ServeHTTP(cleared, httptest.NewRequest(http.MethodGet, "/__admin/requests", nil))
+
+	// Assert
+	requests := []RecordedRequest{}
+	require.NoError(t, json.Unmarshal(journal.Body.Bytes(), &requests))
+	require.Len(t, requests, 1)
+	assert.Equal(t, http.MethodPost, requests[0].Method)
+	assert.Equal(t, "/owners", requests[0].Path)
+	assert.Equal(t, "dryRun=true", requests[0].Query)
+	assert.JSONEq(t, `{"name":"Rex"}`, requests[0].Body)
+	assert.JSONEq(t, `[]`, cleared.Body.String())
+}
+
+func Test_GenerateServerFile_ListsAdminRoutes(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplechaos.yaml", GenerateOptions{MaxRecursionDepth: 1})
+	require.NoError(t, err)
+
+	// Act
+	result, err := GenerateServerFile("http", 5000, "db.json", featureFileDataStructure)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, result, `const adminRoutes = [{"method":"GET","path":"/pets","dbEntry":"pets","responseCode":"200","chaos":{"delayMin":50,"delayMax":60}},`)
+}
@@ -0,0 +1,224 @@
+package genmock
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pb33f/libopenapi/orderedmap"
+	"go.yaml.in/yaml/v4"
+)
+
+var defaultFailureCodes = []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusTooManyRequests}
+
+type Chaos struct {
+	DelayMin     int     `json:"delayMin,omitempty"`
+	DelayMax     int     `json:"delayMax,omitempty"`
+	FailureRate  float64 `json:"failureRate,omitempty"`
+	FailureCodes []int   `json:"failureCodes,omitempty"`
+	ResetRate    float64 `json:"resetRate,omitempty"`
+	TruncateRate float64 `json:"truncateRate,omitempty"`
+}
+
+func ParseDelay(value string) (int, int, error) {
+	if value == "" {
+		return 0, 0, nil
+	}
+	minValue, maxValue, isRange := strings.Cut(value, "-")
+	if !isRange {
+		maxValue = minValue
+	}
+	minDelay, minErr := strconv.Atoi(strings.TrimSpace(minValue))
+	maxDelay, maxErr := strconv.Atoi(strings.TrimSpace(maxValue))
+	if minErr != nil || maxErr != nil || minDelay < 0 || maxDelay < minDelay {
+		return 0, 0, fmt.Errorf("invalid delay '%s', use milliseconds like '200' or a range like '100-500'", value)
+	}
+
+	return minDelay, maxDelay, nil
+}
+
+func (chaos Chaos) check() error {
+	if chaos.DelayMin < 0 || chaos.DelayMax < chaos.DelayMin {
+		return fmt.Errorf("invalid delay %d-%d, the minimum must be positive and not larger than the maximum", chaos.DelayMin, chaos.DelayMax)
+	}
+	rates := []float64{chaos.FailureRate, chaos.ResetRate, chaos.TruncateRate}
+	for i, name := range []string{"failure rate", "reset rate", "truncate rate"} {
+		if rates[i] < 0 || rates[i] > 1 {
+			return fmt.Errorf("invalid %s %v, use a value between 0 and 1", name, rates[i])
+		}
+	}
+	for _, code := range chaos.FailureCodes {
+		if code < 400 || code > 599 {
+			return fmt.Errorf("invalid failure code %d, use a 4xx or 5xx status", code)
+		}
+	}
+
+	return nil
+}
+
+func (chaos Chaos) enabled() bool {
+	return chaos.DelayMax > 0 || chaos.FailureRate > 0 || chaos.ResetRate > 0 || chaos.TruncateRate > 0
+}
+
+func routeChaos(extensions *orderedmap.Map[string, *yaml.Node], opts GenerateOptions) *Chaos {
+	chaos := opts.Chaos
+	chaos.FailureCodes = slices.Clone(opts.Chaos.FailureCodes)
+	extensionNode := func(name string) *yaml.Node {
+		if extensions == nil {
+			return nil
+		}
+		return extensions.GetOrZero(name)
+	}
+	extension := func(name string) (string, bool) {
+		node := extensionNode(name)
+		if node == nil {
+			return "", false
+		}
+		return fmt.Sprint(nodeValue(node)), true
+	}
+	rate := func(name string, value string) float64 {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			opts.fail(fmt.Errorf("invalid %s '%s', use a value between 0 and 1", name, value))
+		}
+		return rate
+	}
+	if value, ok := extension("x-mock-delay"); ok {
+		minDelay, maxDelay, err := ParseDelay(value)
+		if err != nil {
+			opts.fail(fmt.Errorf("x-mock-delay: %w", err))
+		}
+		chaos.DelayMin, chaos.DelayMax = minDelay, maxDelay
+	}
+	if value, ok := extension("x-mock-failure-rate"); ok {
+		chaos.FailureRate = rate("x-mock-failure-rate", value)
+	}
+	if node := extensionNode("x-mock-failure-codes"); node != nil {
+		chaos.FailureCodes = []int{}
+		codes := node.Content
+		if node.Kind == yaml.ScalarNode {
+			codes = []*yaml.Node{node}
+		} else if node.Kind != yaml.SequenceNode {
+			opts.fail(fmt.Errorf("invalid x-mock-failure-codes value, use a list of statuses"))
+		}
+		for _, code := range codes {
+			failureCode, err := strconv.Atoi(code.Value)
+			if err != nil {
+				opts.fail(fmt.Errorf("invalid x-mock-failure-codes value '%s', use a list of statuses", code.Value))
+			}
+			chaos.FailureCodes = append(chaos.FailureCodes, failureCode)
+		}
+	}
+	if value, ok := extension("x-mock-reset-rate"); ok {
+		chaos.ResetRate = rate("x-mock-reset-rate", value)
+	}
+	if value, ok := extension("x-mock-truncate-rate"); ok {
+		chaos.TruncateRate = rate("x-mock-truncate-rate", value)
+	}
+	if err := chaos.check(); err != nil {
+		opts.fail(err)
+	}
+	if !chaos.enabled() {
+		return nil
+	}
+
+	return &chaos
+}
+
+func (s *Server) chaosFor(request *RequestStructure) *Chaos {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Chaos != nil {
+		return s.Chaos
+	}
+	if request != nil {
+		return request.Chaos
+	}
+
+	return nil
+}
+
+func (chaos Chaos) inject(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, bool) {
+	if chaos.DelayMax > 0 {
+		// #nosec G404 // Not a security risk, the delay only simulates latency
+		delay := time.Duration(chaos.DelayMin+rand.IntN(chaos.DelayMax-chaos.DelayMin+1)) * time.Millisecond
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return w, true
+		}
+	}
+	// #nosec G404 // Not a security risk, the rates only simulate failures
+	if rand.Float64() < chaos.ResetRate {
+		resetConnection(w)
+		return w, true
+	}
+	// #nosec G404 // Not a security risk, the rates only simulate failures
+	if rand.Float64() < chaos.FailureRate {
+		failureCodes := chaos.FailureCodes
+		if len(failureCodes) == 0 {
+			failureCodes = defaultFailureCodes
+		}
+		// #nosec G404 // Not a security risk, the rates only simulate failures
+		failureCode := failureCodes[rand.IntN(len(failureCodes))]
+		if failureCode == http.StatusTooManyRequests {
+			w.Header().Set("Retry-After", "1")
+		}
+		writeJson(w, failureCode, map[string]any{"message": "injected failure"})
+		return w, true
+	}
+	// #nosec G404 // Not a security risk, the rates only simulate failures
+	if rand.Float64() < chaos.TruncateRate {
+		return &truncatedWriter{ResponseWriter: w}, false
+	}
+
+	return w, false
+}
+
+func resetConnection(w http.ResponseWriter) {
+	if hijacker, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hijacker.Hijack(); err == nil {
+			if tcpConn, ok := conn.(*net.TCPConn); ok {
+				_ = tcpConn.SetLinger(0)
+			}
+			_ = conn.Close()
+			return
+		}
+	}
+	panic(http.ErrAbortHandler)
+}
+
+type truncatedWriter struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (tw *truncatedWriter) WriteHeader(statusCode int) {
+	if tw.statusCode == 0 {
+		tw.statusCode = statusCode
+	}
+}
+
+func (tw *truncatedWriter) Write(content []byte) (int, error) {
+	return tw.body.Write(content)
+}
+
+func (tw *truncatedWriter) abort() {
+	if tw.statusCode == 0 {
+		tw.statusCode = http.StatusOK
+	}
+	tw.Header().Set("Content-Length", strconv.Itoa(tw.body.Len()))
+	tw.ResponseWriter.WriteHeader(tw.statusCode)
+	_, _ = tw.ResponseWriter.Write(tw.body.Bytes()[:tw.body.Len()/2])
+	if flusher, ok := tw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+	panic(http.ErrAbortHandler)
+}
@@ -0,0 +1,250 @@
+package genmock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func Test_ParseDelay(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value       string
+		expectedMin int
+		expectedMax int
+		expectedErr bool
+	}{
+		"empty":    {value: ""},
+		"fixed":    {value: "200", expectedMin: 200, expectedMax: 200},
+		"range":    {value: "100-500", expectedMin: 100, expectedMax: 500},
+		"reversed": {value: "500-100", expectedErr: true},
+		"invalid":  {value: "slow", expectedErr: true},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			minDelay, maxDelay, err := ParseDelay(data.value)
+
+			// Assert
+			if data.expectedErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, data.expectedMin, minDelay)
+			assert.Equal(t, data.expectedMax, maxDelay)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_ReadsChaosExtensions(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		specFilename  string
+		chaos         Chaos
+		method        string
+		path          string
+		expectedChaos *Chaos
+		expectedErr   string
+	}{
+		"delay extension": {
+			specFilename:  "./testdata/examplechaos.yaml",
+			method:        "get",
+			path:          "/pets",
+			expectedChaos: &Chaos{DelayMin: 50, DelayMax: 60},
+		},
+		"failure extension": {
+			specFilename:  "./testdata/examplechaos.yaml",
+			method:        "post",
+			path:          "/pets",
+			expectedChaos: &Chaos{FailureRate: 1, FailureCodes: []int{503}},
+		},
+		"single failure code": {
+			specFilename:  "./testdata/examplechaoscode.yaml",
+			method:        "post",
+			path:          "/pets",
+			expectedChaos: &Chaos{FailureRate: 1, FailureCodes: []int{429}},
+		},
+		"no chaos": {
+			specFilename: "./testdata/examplechaos.yaml",
+			method:       "get",
+			path:         "/pets/:petId",
+		},
+		"options": {
+			specFilename:  "./testdata/examplechaos.yaml",
+			chaos:         Chaos{TruncateRate: 0.5},
+			method:        "get",
+			path:          "/pets/:petId",
+			expectedChaos: &Chaos{TruncateRate: 0.5},
+		},
+		"extension overrides options": {
+			specFilename:  "./testdata/examplechaos.yaml",
+			chaos:         Chaos{DelayMin: 10, DelayMax: 10, ResetRate: 0.1},
+			method:        "get",
+			path:          "/pets",
+			expectedChaos: &Chaos{DelayMin: 50, DelayMax: 60, ResetRate: 0.1},
+		},
+		"invalid option": {
+			specFilename: "./testdata/examplechaos.yaml",
+			chaos:        Chaos{FailureRate: 2},
+			expectedErr:  "invalid failure rate 2",
+		},
+		"invalid extension": {
+			specFilename: "./testdata/examplechaosinvalid.yaml",
+			expectedErr:  "invalid x-mock-failure-rate 'lots'",
+		},
+		"invalid failure codes": {
+			specFilename: "./testdata/examplechaoscodesinvalid.yaml",
+			expectedErr:  "invalid x-mock-failure-codes value, use a list of statuses",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap(data.specFilename, GenerateOptions{MaxRecursionDepth: 1, Chaos: data.chaos})
+
+			// Assert
+			if data.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), data.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.NotEmpty(t, resultMap[data.method][data.path])
+			assert.Equal(t, data.expectedChaos, resultMap[data.method][data.path][0].Chaos)
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_InjectsChaos(t *testing.T) {
+	t.Parallel()
+
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplechaos.yaml", GenerateOptions{MaxRecursionDepth: 1, GenExamples: true})
+	require.NoError(t, err)
+
+	t.Run("failure", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		server := NewServer(featureFileDataStructure)
+		recorder := httptest.NewRecorder()
+
+		// Act
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/pets", strings.NewReader(`{"name":"Rex"}`)))
+
+		// Assert
+		assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+		assert.JSONEq(t, `{"message":"injected failure"}`, recorder.Body.String())
+	})
+
+	t.Run("retry after", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		server := NewServer(featureFileDataStructure)
+		server.Chaos = &Chaos{FailureRate: 1, FailureCodes: []int{http.StatusTooManyRequests}}
+		recorder := httptest.NewRecorder()
+
+		// Act
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/pets/1", nil))
+
+		// Assert
+		assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+		assert.Equal(t, "1", recorder.Header().Get("Retry-After"))
+	})
+
+	t.Run("delay", func(t *testing.T) {
+		t.Parallel()
+
+		// Arrange
+		server := NewServer(featureFileDataStructure)
+		recorder := httptest.NewRecorder()
+		start := time.Now()
+
+		// Act
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/pets", nil))
+
+		// Assert
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+	})
+
+	tests := map[string]struct {
+		chaos *Chaos
+	}{
+		"reset":    {chaos: &Chaos{ResetRate: 1}},
+		"truncate": {chaos: &Chaos{TruncateRate: 1}},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := NewServer(featureFileDataStructure)
+			server.Chaos = data.chaos
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+
+			// Act
+			response, err := http.Get(httpServer.URL + "/pets/1")
+			if err == nil {
+				_, err = io.ReadAll(response.Body)
+				_ = response.Body.Close()
+			}
+
+			// Assert
+			require.Error(t, err)
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_AdminChaos(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplechaos.yaml", GenerateOptions{MaxRecursionDepth: 1})
+	require.NoError(t, err)
+	server := NewServer(featureFileDataStructure)
+	serve := func(method string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(method, "/__admin/chaos", strings.NewReader(body)))
+		return recorder
+	}
+
+	// Act
+	invalid := serve(http.MethodPut, `{"failureRate": 3}`)
+	put := serve(http.MethodPut, `{"failureRate": 1, "failureCodes": [502]}`)
+	get := serve(http.MethodGet, "")
+	failed := httptest.NewRecorder()
+	server.ServeHTTP(failed, httptest.NewRequest(http.MethodGet, "/pets/1", nil))
+	deleted := serve(http.MethodDelete, "")
+	cleared := serve(http.MethodGet, "")
+	recovered := httptest.NewRecorder()
+	server.ServeHTTP(recovered, httptest.NewRequest(http.MethodGet, "/pets/1", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, invalid.Code)
+	assert.JSONEq(t, `{"message":"invalid failure rate 3, use a value between 0 and 1"}`, invalid.Body.String())
+	assert.Equal(t, http.StatusOK, put.Code)
+	assert.JSONEq(t, `{"failureRate":1,"failureCodes":[502]}`, get.Body.String())
+	assert.Equal(t, http.StatusBadGateway, failed.Code)
+	assert.Equal(t, http.StatusNoContent, deleted.Code)
+	assert.Equal(t, "null\n", cleared.Body.String())
+	assert.Equal(t, http.StatusOK, recovered.Code)
+	assert.Len(t, server.Requests(), 2)
+}
@@ -1,7 +1,9 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"log"
 	"os"
 
 	genmock "github.com/bramca/gen-mockserver"
@@ -9,22 +11,59 @@ import (
 )
 
 var opts struct {
-	SpecFile         string `short:"s" long:"specfile" description:"[required] path to your openapi specification file" required:"true"`
-	SpecMajorVersion int    `short:"v" long:"specversion" choice:"2" choice:"3" description:"[required] specify the major version of your spec" required:"true"`
-	Scheme           string `short:"c" long:"scheme" default:"http" choice:"http" choice:"https" description:"[optional] specify the scheme that should be used by the mock server" required:"true"`
-	Port             int    `short:"p" long:"port" default:"5000" description:"[optional] specify the port that should be used by the mock server"`
-	DbFile           string `short:"d" long:"dbfile" default:"db.json" description:"[optional] filename for the generated database (use the .json file extension)"`
-	ServerFile       string `short:"f" long:"serverfile" default:"server.js" description:"[optional] filename for the generated server (use the .js file extension)"`
-	RecursionDepth   int    `short:"r" long:"recursiondepth" default:"0" description:"[optional] give the maximum recursion depth to generate the response json (default 0)"`
-	GenFakeExamples  bool   `short:"e" long:"exampledata" description:"[optional] generate fake example data in the responses"`
+	SpecFile         string   `short:"s" long:"specfile" description:"[required] path to your openapi specification file" required:"true"`
+	SpecMajorVersion int      `short:"v" long:"specversion" choice:"2" choice:"3" description:"[optional] read the spec as this major version instead of detecting it from the swagger/openapi root key"`
+	Scheme           string   `short:"c" long:"scheme" default:"http" choice:"http" choice:"https" description:"[optional] specify the scheme that should be used by the mock server" required:"true"`
+	Port             int      `short:"p" long:"port" default:"5000" description:"[optional] specify the port that should be used by the mock server"`
+	DbFile           string   `short:"d" long:"dbfile" default:"db.json" description:"[optional] filename for the generated database (use the .json file extension)"`
+	ServerFile       string   `short:"f" long:"serverfile" default:"server.js" description:"[optional] filename for the generated server (use the .js file extension)"`
+	RecursionDepth   int      `short:"r" long:"recursiondepth" default:"0" description:"[optional] give the maximum recursion depth to generate the response json (default 0)"`
+	GenFakeExamples  bool     `short:"e" long:"exampledata" description:"[optional] generate fake example data in the responses"`
+	PreferNull       bool     `short:"n" long:"prefernull" description:"[optional] generate null for nullable fields instead of a value"`
+	UnionBranch      string   `short:"u" long:"unionbranch" default:"first" description:"[optional] branch to generate for oneOf/anyOf schemas: first, random or a branch index"`
+	AllBranches      bool     `short:"a" long:"allbranches" description:"[optional] generate a response for every oneOf/anyOf branch and cycle through them"`
+	MapKeys          int      `short:"k" long:"mapkeys" default:"1" description:"[optional] number of keys to generate for map schemas (additionalProperties), bounded by minProperties/maxProperties"`
+	ArrayItems       string   `short:"i" long:"arrayitems" default:"1" description:"[optional] number of items to generate for arrays, a count (3) or a range (1-5), bounded by minItems/maxItems"`
+	NameRules        []string `short:"g" long:"namerule" description:"[optional] generate example data with a gofakeit function for property names matching a regex, format: <regex>=<gofakeit function> (can be repeated)"`
+	Seed             uint64   `long:"seed" description:"[optional] seed for the generated data, the same spec and seed always generate the same files (default random)"`
+	DbRecords        int      `short:"b" long:"dbrecords" default:"0" description:"[optional] number of records to generate for every collection in the database file from the GET response schema"`
+	AllResponses     bool     `short:"o" long:"allresponses" description:"[optional] mock every declared response status, selectable per request with a 'Prefer: code=<status>' header or a '__code=<status>' query parameter"`
+	StatusFile       string   `short:"t" long:"statusfile" description:"[optional] json file with a status per route (e.g. {\"GET /pets/:petId\": 404}) that is returned by 'genmock serve' (requires -o)"`
+	Validate         bool     `short:"l" long:"validate" description:"[optional] validate path, query, header and cookie parameters and json bodies against the spec and answer invalid requests with 400/422"`
+	Delay            string   `long:"delay" description:"[optional] delay of every response in milliseconds, a fixed delay (200) or a range (100-500) from which the delay is picked at random"`
+	FailureRate      float64  `long:"failurerate" description:"[optional] fraction of the requests (0-1) that is answered with a random failure status"`
+	FailureCodes     []int    `long:"failurecode" description:"[optional] status used for injected failures, picked at random when repeated (default 500, 502, 503, 504 and 429)"`
+	ResetRate        float64  `long:"resetrate" description:"[optional] fraction of the requests (0-1) whose connection is reset without a response"`
+	TruncateRate     float64  `long:"truncaterate" description:"[optional] fraction of the requests (0-1) whose response body is cut off halfway"`
+	Stateful         bool     `long:"stateful" description:"[optional] store created, replaced, updated and deleted records for collection/item path pairs (e.g. /pets and /pets/{petId}) and answer with the stored records"`
+	ScenarioFile     string   `long:"scenariofile" description:"[optional] yaml file with scenarios that switch the responses of operations (by operationId) between states when requests are made"`
+	ExampleName      string   `short:"x" long:"examplename" description:"[optional] name of the spec example to use as default response when multiple named examples exist (requires -e)"`
 }
 
+var serveCommand struct{}
+
 func main() {
-	_, err := flags.Parse(&opts)
+	parser := flags.NewParser(&opts, flags.Default)
+	parser.SubcommandsOptional = true
+	_, err := parser.AddCommand("serve", "serve the mock directly", "Serve the mock from an in-process Go http server instead of generating a json-server project", &serveCommand)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Something went wrong with the argument parsing: %v", err)
+		os.Exit(1)
+	}
+	_, err = parser.Parse()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Something went wrong with the argument parsing: %v", err)
 		os.Exit(1)
 	}
+	serve := parser.Active != nil && parser.Active.Name == "serve"
+	if opts.StatusFile != "" && !opts.AllResponses {
+		fmt.Fprint(os.Stderr, "Something went wrong with the argument parsing: -statusfile requires -allresponses")
+		os.Exit(1)
+	}
+	if opts.StatusFile != "" && !serve {
+		fmt.Fprint(os.Stderr, "Something went wrong with the argument parsing: -statusfile is only used by serve, start the generated server with the STATUS_FILE environment variable instead")
+		os.Exit(1)
+	}
 	specFile := opts.SpecFile
 	specMajorVersion := opts.SpecMajorVersion
 	scheme := opts.Scheme
@@ -32,20 +71,76 @@ func main() {
 	dbFile := opts.DbFile
 	serverFile := opts.ServerFile
 	maxRecursionDepth := opts.RecursionDepth
-	var featureFileDataStructure map[string]map[string][]genmock.RequestStructure
-	if specMajorVersion == 2 {
-		featureFileDataStructure, err = genmock.SpecV2toRequestStructureMap(specFile, maxRecursionDepth, opts.GenFakeExamples)
+	nameRules := []genmock.NameRule{}
+	for _, nameRule := range opts.NameRules {
+		rule, err := genmock.ParseNameRule(nameRule)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Something went wrong with the argument parsing: %v", err)
+			os.Exit(1)
+		}
+		nameRules = append(nameRules, rule)
+	}
+	delayMin, delayMax, err := genmock.ParseDelay(opts.Delay)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Something went wrong with the argument parsing: %v", err)
+		os.Exit(1)
+	}
+	scenarios := []genmock.Scenario{}
+	if opts.ScenarioFile != "" {
+		scenarios, err = genmock.LoadScenarios(opts.ScenarioFile)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Something went wrong with parsing the spec file: %v", err)
+			fmt.Fprintf(os.Stderr, "Something went wrong with reading the scenario file: %v", err)
 			os.Exit(1)
 		}
 	}
-	if specMajorVersion == 3 {
-		featureFileDataStructure, err = genmock.SpecV3toRequestStructureMap(specFile, maxRecursionDepth, opts.GenFakeExamples)
+	featureFileDataStructure, err := genmock.SpecToRequestStructureMap(specFile, genmock.GenerateOptions{
+		SpecMajorVersion:  specMajorVersion,
+		MaxRecursionDepth: maxRecursionDepth,
+		GenExamples:       opts.GenFakeExamples,
+		PreferNull:        opts.PreferNull,
+		UnionBranch:       opts.UnionBranch,
+		AllBranches:       opts.AllBranches,
+		ExampleName:       opts.ExampleName,
+		MapKeys:           opts.MapKeys,
+		ArrayItems:        opts.ArrayItems,
+		NameRules:         nameRules,
+		Seed:              opts.Seed,
+		DbRecords:         opts.DbRecords,
+		AllResponses:      opts.AllResponses,
+		Validate:          opts.Validate,
+		Stateful:          opts.Stateful,
+		Scenarios:         scenarios,
+		Chaos: genmock.Chaos{
+			DelayMin:     delayMin,
+			DelayMax:     delayMax,
+			FailureRate:  opts.FailureRate,
+			FailureCodes: opts.FailureCodes,
+			ResetRate:    opts.ResetRate,
+			TruncateRate: opts.TruncateRate,
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Something went wrong with parsing the spec file: %v", err)
+		os.Exit(1)
+	}
+
+	if serve {
+		server := genmock.NewServer(featureFileDataStructure)
+		server.Logger = log.New(os.Stdout, "", log.LstdFlags)
+		if opts.StatusFile != "" {
+			server.StatusOverrides, err = readStatusFile(opts.StatusFile)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Something went wrong with reading the status file: %v", err)
+				os.Exit(1)
+			}
+		}
+		fmt.Printf("Serving mock on %s://localhost:%d\n", scheme, port)
+		err = server.ListenAndServe(scheme, port)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Something went wrong with parsing the spec file: %v", err)
+			fmt.Fprintf(os.Stderr, "Something went wrong with serving the mock: %v", err)
 			os.Exit(1)
 		}
+		return
 	}
 
 	featureFileContent, err := genmock.GenerateServerFile(scheme, port, dbFile, featureFileDataStructure)
@@ -93,3 +188,21 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+func readStatusFile(filename string) (map[string]string, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	statuses := map[string]any{}
+	err = json.Unmarshal(content, &statuses)
+	if err != nil {
+		return nil, err
+	}
+	statusOverrides := map[string]string{}
+	for route, status := range statuses {
+		statusOverrides[route] = fmt.Sprint(status)
+	}
+
+	return statusOverrides, nil
+}
@@ -0,0 +1,126 @@
+package genmock
+
+import (
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	collectionActions = map[string]string{"get": "list", "post": "create"}
+	itemActions       = map[string]string{"get": "read", "put": "replace", "patch": "update", "delete": "delete"}
+)
+
+type CrudOperation struct {
+	Action  string `json:"action"`
+	IdParam string `json:"idParam"`
+}
+
+func markCrudOperations(featureFileDataStructure map[string]map[string][]RequestStructure) {
+	paths := map[string]bool{}
+	for _, calls := range featureFileDataStructure {
+		for path := range calls {
+			paths[path] = true
+		}
+	}
+	for method, calls := range featureFileDataStructure {
+		for path, requests := range calls {
+			segments := splitPath(path)
+			if len(segments) == 0 {
+				continue
+			}
+			action := ""
+			idParam, isItem := strings.CutPrefix(segments[len(segments)-1], ":")
+			if isItem && paths["/"+strings.Join(segments[:len(segments)-1], "/")] {
+				action = itemActions[method]
+			}
+			if !isItem {
+				idParam = crudItemParam(paths, path)
+				if idParam != "" {
+					action = collectionActions[method]
+				}
+			}
+			if action == "" {
+				continue
+			}
+			for i := range requests {
+				requests[i].Crud = &CrudOperation{Action: action, IdParam: idParam}
+			}
+		}
+	}
+}
+
+func crudItemParam(paths map[string]bool, collectionPath string) string {
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		param, ok := strings.CutPrefix(path, strings.TrimSuffix(collectionPath, "/")+"/:")
+		if ok && !strings.Contains(param, "/") {
+			return param
+		}
+	}
+
+	return ""
+}
+
+func (s *Server) serveCrud(w http.ResponseWriter, r *http.Request, request RequestStructure, pathParams []string) {
+	id, parents := collectionParams(request, pathParams)
+	idField := ""
+	if template, ok := request.ResponseBody.(map[string]any); ok && request.Crud.IdParam != "id" {
+		if _, ok := template[request.Crud.IdParam]; ok {
+			idField = request.Crud.IdParam
+		}
+	}
+	statusCode, entity := s.collectionResponse(r, request.DbEntry, id, parents, idField)
+	if statusCode >= 300 {
+		writeJson(w, statusCode, entity)
+		return
+	}
+	if responseCode, err := strconv.Atoi(request.ResponseCode); err == nil {
+		statusCode = responseCode
+	}
+	var responseBody any
+	if request.ResponseBody != nil {
+		responseBody = shapeEntity(request.ResponseBody, entity)
+	}
+	for name, value := range request.ResponseHeaders {
+		w.Header().Set(name, headerValue(name, value, responseBody, strings.Split(request.Path, "?")[0]))
+	}
+	if responseBody == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+	writeJson(w, statusCode, responseBody)
+}
+
+func shapeEntity(template any, entity any) any {
+	switch typedTemplate := template.(type) {
+	case []any:
+		entities, ok := entity.([]any)
+		if !ok {
+			return entity
+		}
+		shaped := []any{}
+		for _, item := range entities {
+			if len(typedTemplate) > 0 {
+				item = shapeEntity(typedTemplate[0], item)
+			}
+			shaped = append(shaped, item)
+		}
+		return shaped
+	case map[string]any:
+		entityMap, ok := entity.(map[string]any)
+		if !ok {
+			return entity
+		}
+		shaped := map[string]any{}
+		for key, value := range typedTemplate {
+			if entityValue, ok := entityMap[key]; ok {
+				shaped[key] = shapeEntity(value, entityValue)
+			}
+		}
+		return shaped
+	}
+
+	return entity
+}
@@ -0,0 +1,191 @@
+package genmock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func Test_SpecToRequestStructureMap_MarksCrudOperations(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		stateful     bool
+		method       string
+		path         string
+		expectedCrud *CrudOperation
+	}{
+		"list":              {stateful: true, method: "get", path: "/pets", expectedCrud: &CrudOperation{Action: "list", IdParam: "petId"}},
+		"create":            {stateful: true, method: "post", path: "/pets", expectedCrud: &CrudOperation{Action: "create", IdParam: "petId"}},
+		"read":              {stateful: true, method: "get", path: "/pets/:petId", expectedCrud: &CrudOperation{Action: "read", IdParam: "petId"}},
+		"replace":           {stateful: true, method: "put", path: "/pets/:petId", expectedCrud: &CrudOperation{Action: "replace", IdParam: "petId"}},
+		"update":            {stateful: true, method: "patch", path: "/pets/:petId", expectedCrud: &CrudOperation{Action: "update", IdParam: "petId"}},
+		"delete":            {stateful: true, method: "delete", path: "/pets/:petId", expectedCrud: &CrudOperation{Action: "delete", IdParam: "petId"}},
+		"nested collection": {stateful: true, method: "post", path: "/owners/:ownerId/pets", expectedCrud: &CrudOperation{Action: "create", IdParam: "petId"}},
+		"nested item":       {stateful: true, method: "get", path: "/owners/:ownerId/pets/:petId", expectedCrud: &CrudOperation{Action: "read", IdParam: "petId"}},
+		"no pair":           {stateful: true, method: "get", path: "/health"},
+		"not stateful":      {method: "get", path: "/pets/:petId"},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap("./testdata/examplecrud.yaml", GenerateOptions{MaxRecursionDepth: 1, Stateful: data.stateful})
+
+			// Assert
+			require.NoError(t, err)
+			require.NotEmpty(t, resultMap[data.method][data.path])
+			assert.Equal(t, data.expectedCrud, resultMap[data.method][data.path][0].Crud)
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_Crud(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplecrud.yaml", GenerateOptions{MaxRecursionDepth: 1, Stateful: true})
+	require.NoError(t, err)
+	server := NewServer(featureFileDataStructure)
+	serve := func(method string, path string, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	// Act
+	created := serve(http.MethodPost, "/pets", `{"name":"Rex","owner":"Alice"}`)
+	listed := serve(http.MethodGet, "/pets", "")
+	updated := serve(http.MethodPatch, "/pets/1", `{"tag":"dog"}`)
+	read := serve(http.MethodGet, "/pets/1", "")
+	replaced := serve(http.MethodPut, "/pets/1", `{"name":"Max"}`)
+	deleted := serve(http.MethodDelete, "/pets/1", "")
+	missing := serve(http.MethodGet, "/pets/1", "")
+	missingUpdate := serve(http.MethodPatch, "/pets/1", `{"tag":"cat"}`)
+	missingDelete := serve(http.MethodDelete, "/pets/1", "")
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, created.Code)
+	assert.JSONEq(t, `{"id":1,"petId":1,"name":"Rex"}`, created.Body.String())
+	assert.Equal(t, "/pets/1", created.Header().Get("Location"))
+	assert.Equal(t, http.StatusOK, listed.Code)
+	assert.JSONEq(t, `[{"id":1,"petId":1,"name":"Rex"}]`, listed.Body.String())
+	assert.Equal(t, "1", listed.Header().Get("X-Total-Count"))
+	assert.JSONEq(t, `{"id":1,"petId":1,"name":"Rex","tag":"dog"}`, updated.Body.String())
+	assert.JSONEq(t, `{"id":1,"petId":1,"name":"Rex","tag":"dog"}`, read.Body.String())
+	assert.JSONEq(t, `{"id":1,"petId":1,"name":"Max"}`, replaced.Body.String())
+	assert.Equal(t, http.StatusNoContent, deleted.Code)
+	assert.Empty(t, deleted.Body.String())
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+	assert.Equal(t, http.StatusNotFound, missingUpdate.Code)
+	assert.Equal(t, http.StatusNotFound, missingDelete.Code)
+}
+
+func Test_Server_ServeHTTP_CrudNested(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		"owner collection": {path: "/owners/7/pets", expectedCode: http.StatusOK, expectedBody: `[{"id":1,"petId":1,"name":"Rex"}]`},
+		"other owner":      {path: "/owners/8/pets", expectedCode: http.StatusOK, expectedBody: `[]`},
+		"owner item":       {path: "/owners/7/pets/1", expectedCode: http.StatusOK, expectedBody: `{"id":1,"petId":1,"name":"Rex"}`},
+		"other owner item": {path: "/owners/8/pets/1", expectedCode: http.StatusNotFound, expectedBody: `{}`},
+	}
+
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplecrud.yaml", GenerateOptions{MaxRecursionDepth: 1, Stateful: true})
+	require.NoError(t, err)
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := NewServer(featureFileDataStructure)
+			server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/owners/7/pets", strings.NewReader(`{"name":"Rex"}`)))
+			recorder := httptest.NewRecorder()
+
+			// Act
+			server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, data.path, nil))
+
+			// Assert
+			assert.Equal(t, data.expectedCode, recorder.Code)
+			assert.JSONEq(t, data.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func Test_shapeEntity(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		template any
+		entity   any
+		expected any
+	}{
+		"drops unknown fields": {
+			template: map[string]any{"id": 0, "name": ""},
+			entity:   map[string]any{"id": 1, "name": "Rex", "owner": "Alice"},
+			expected: map[string]any{"id": 1, "name": "Rex"},
+		},
+		"skips missing fields": {
+			template: map[string]any{"id": 0, "tag": ""},
+			entity:   map[string]any{"id": 1},
+			expected: map[string]any{"id": 1},
+		},
+		"shapes nested objects": {
+			template: map[string]any{"owner": map[string]any{"name": ""}},
+			entity:   map[string]any{"owner": map[string]any{"name": "Alice", "age": 30}},
+			expected: map[string]any{"owner": map[string]any{"name": "Alice"}},
+		},
+		"shapes array items": {
+			template: []any{map[string]any{"id": 0}},
+			entity:   []any{map[string]any{"id": 1, "name": "Rex"}, map[string]any{"id": 2}},
+			expected: []any{map[string]any{"id": 1}, map[string]any{"id": 2}},
+		},
+		"keeps unshaped values": {
+			template: map[string]any{"owner": nil},
+			entity:   map[string]any{"owner": map[string]any{"name": "Alice"}},
+			expected: map[string]any{"owner": map[string]any{"name": "Alice"}},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result := shapeEntity(data.template, data.entity)
+
+			// Assert
+			assert.Equal(t, data.expected, result)
+		})
+	}
+}
+
+func Test_GenerateServerFile_CrudRoutes(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplecrud.yaml", GenerateOptions{MaxRecursionDepth: 1, Stateful: true})
+	require.NoError(t, err)
+
+	// Act
+	result, err := GenerateServerFile("http", 5000, "db.json", featureFileDataStructure)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, result, `[statusCode, responseBody] = crudResponse(req, 'create', 'pets', {}, 'petId', 201, {`)
+	assert.Contains(t, result, `[statusCode, responseBody] = crudResponse(req, 'delete', 'pets', {}, 'petId', 204, undefined);`)
+	assert.Contains(t, result, `[statusCode, responseBody] = crudResponse(req, 'read', 'owners-pets', {"ownerId":"ownerId"}, 'petId', 200, {`)
+	assert.Contains(t, result, `responseHeaders = statusCode < 300 ? crudHeaders(responseHeaders, responseBody, '/pets') : {};`)
+	assert.NotContains(t, result, `crudResponse(req, 'read', 'health'`)
+}
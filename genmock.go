@@ -1,19 +1,32 @@
 package genmock
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
+	"maps"
+	"math"
 	"math/rand/v2"
+	"net/http"
 	"os"
+	"path/filepath"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v7"
 	"github.com/pb33f/libopenapi"
+	"github.com/pb33f/libopenapi/datamodel"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v2high "github.com/pb33f/libopenapi/datamodel/high/v2"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
 	"github.com/pb33f/libopenapi/orderedmap"
+	"github.com/pb33f/libopenapi/utils"
 	orderedmapv2 "github.com/pb33f/ordered-map/v2"
+	"go.yaml.in/yaml/v4"
 )
 
 const (
@@ -21,6 +34,9 @@ const (
 	keyFile     = "key.pem"
 	certFile    = "cert.pem"
 
+	defaultMapKeys    = 1
+	defaultArrayItems = 1
+
 	initServerTemplateHttp = `
 const fs = require('fs');
 const jsonServer = require('json-server');
@@ -60,19 +76,702 @@ function checkWriteToDb() {
 	}
 }
 
+const variantCalls = {};
+function nextVariant(route, variants) {
+	variantCalls[route] = (variantCalls[route] || 0) + 1;
+	return variants[(variantCalls[route] - 1) %% variants.length];
+}
+
+function preferredExample(req, examples, fallback) {
+	const prefer = /example=([^;,\s]+)/.exec(req.get('Prefer') || '');
+	if (prefer && prefer[1] in examples) {
+		return examples[prefer[1]];
+	}
+	return fallback;
+}
+
+function seededRecords(req, collection, parents, idParam) {
+	const records = (router.db.get(collection).value() || []).filter((record) =>
+		Object.keys(parents).every((field) => String(record[field]) === req.params[parents[field]]) &&
+		Object.keys(req.query).every((key) => key.startsWith('_') || String(record[key]) === String(req.query[key])));
+	if (idParam) {
+		return records.find((record) => String(record.id) === req.params[idParam]);
+	}
+	return records;
+}
+
+function shapeEntity(template, entity) {
+	if (Array.isArray(template)) {
+		return Array.isArray(entity) ? entity.map((item) => template.length > 0 ? shapeEntity(template[0], item) : item) : entity;
+	}
+	if (template !== null && typeof template === 'object') {
+		if (entity === null || typeof entity !== 'object' || Array.isArray(entity)) {
+			return entity;
+		}
+		const shaped = {};
+		Object.keys(template).filter((key) => key in entity).forEach((key) => {
+			shaped[key] = shapeEntity(template[key], entity[key]);
+		});
+		return shaped;
+	}
+	return entity;
+}
+
+function nextId(records) {
+	if (records.every((record) => typeof record.id === 'number')) {
+		return Math.max(0, ...records.map((record) => record.id)) + 1;
+	}
+	return Math.random().toString(36).slice(2, 9);
+}
+
+function crudResponse(req, action, collection, parents, idParam, statusCode, template) {
+	const state = router.db.getState();
+	const records = state[collection] = state[collection] || [];
+	const inParents = (record) => Object.keys(parents).every((field) => String(record[field]) === req.params[parents[field]]);
+	const idField = idParam !== 'id' && template !== null && typeof template === 'object' && !Array.isArray(template) && idParam in template ? idParam : '';
+	const shaped = (entity) => [statusCode, template === undefined ? undefined : shapeEntity(template, entity)];
+	const complete = (record) => {
+		Object.keys(parents).forEach((field) => {
+			if (record[field] === undefined) {
+				record[field] = /^-?\d+$/.test(req.params[parents[field]]) ? Number(req.params[parents[field]]) : req.params[parents[field]];
+			}
+		});
+		if (idField && record[idField] === undefined) {
+			record[idField] = record.id;
+		}
+		return record;
+	};
+	if (action === 'list') {
+		return shaped(records.filter((record) => inParents(record) &&
+			Object.keys(req.query).every((key) => key.startsWith('_') || String(record[key]) === String(req.query[key]))));
+	}
+	if (action === 'create') {
+		const record = Object.assign({}, req.body);
+		if (record.id === undefined) {
+			record.id = nextId(records);
+		}
+		records.push(complete(record));
+		router.db.write();
+		return shaped(record);
+	}
+	const index = records.findIndex((record) => String(record.id) === req.params[idParam] && inParents(record));
+	if (index < 0) {
+		return [404, {}];
+	}
+	const record = records[index];
+	if (action === 'read') {
+		return shaped(record);
+	}
+	if (action === 'delete') {
+		records.splice(index, 1);
+		router.db.write();
+		return shaped(record);
+	}
+	records[index] = action === 'update' ? Object.assign(record, req.body, {id: record.id}) : complete(Object.assign({}, req.body, {id: record.id}));
+	router.db.write();
+	return shaped(records[index]);
+}
+
+function crudHeaders(headers, body, path) {
+	const values = {};
+	Object.keys(headers).forEach((name) => {
+		values[name] = headers[name];
+		if (name.toLowerCase() === 'etag' && body !== undefined) {
+			let hash = 0xcbf29ce484222325n;
+			for (const byte of Buffer.from(JSON.stringify(body))) {
+				hash = BigInt.asUintN(64, (hash ^ BigInt(byte)) * 0x100000001b3n);
+			}
+			values[name] = '"' + hash.toString(16) + '"';
+		}
+		if (name.toLowerCase() === 'x-total-count' && Array.isArray(body)) {
+			values[name] = String(body.length);
+		}
+		if (name.toLowerCase() === 'location' && body && body.id !== undefined && !path.includes(':')) {
+			values[name] = path.replace(/\/$/, '') + '/' + body.id;
+		}
+	});
+	return values;
+}
+
+const statusOverrides = process.env.STATUS_FILE ? JSON.parse(fs.readFileSync(process.env.STATUS_FILE)) : {};
+function selectResponse(req, route, statusCode, responseBody, responseHeaders, responses, statusHeaders) {
+	const prefer = /(?:^|[;,\s])code=(\d{3})/.exec(req.get('Prefer') || '');
+	const code = String((prefer && prefer[1]) || req.query.__code || statusOverrides[route] || '');
+	const key = [code, code.charAt(0) + 'XX', 'default'].find((candidate) => code && candidate in responses);
+	if (key === undefined) {
+		return [statusCode, responseBody, responseHeaders];
+	}
+	return [Number(code), responses[key] === null ? undefined : responses[key], statusHeaders[key] || {}];
+}
+
+function sendContent(req, res, statusCode, content) {
+	const mediaTypes = content.map((entry) => entry.mediaType);
+	const mediaType = req.accepts(mediaTypes);
+	if (!mediaType) {
+		res.status(406).json({message: 'not acceptable', accepted: mediaTypes});
+		return true;
+	}
+	const entry = content.find((candidate) => candidate.mediaType === mediaType);
+	if (entry.json) {
+		return false;
+	}
+	res.status(statusCode).type(entry.contentType).send(entry.binary ? Buffer.from(entry.body || '', 'base64') : entry.body || '');
+	return true;
+}
+
+const formatChecks = {
+	'date-time': (value) => /^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$/i.test(value) && !isNaN(Date.parse(value)),
+	'date': (value) => /^\d{4}-\d{2}-\d{2}$/.test(value) && !isNaN(Date.parse(value)),
+	'uuid': (value) => /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/i.test(value),
+	'email': (value) => /^[^\s@]+@[^\s@]+$/.test(value),
+	'ipv4': (value) => require('net').isIPv4(value),
+	'ipv6': (value) => require('net').isIPv6(value),
+	'uri': (value) => {
+		try {
+			return new URL(value).protocol !== '';
+		} catch (err) {
+			return false;
+		}
+	},
+};
+
+function pointerToken(token) {
+	return String(token).replace(/~/g, '~0').replace(/\//g, '~1');
+}
+
+function validationErrors(rule, value, pointer) {
+	if (!rule) {
+		return [];
+	}
+	const valueType = value === null || value === undefined ? 'null' : Array.isArray(value) ? 'array' : Number.isInteger(value) ? 'integer' : typeof value;
+	if (valueType === 'null') {
+		return rule.nullable || !rule.type ? [] : [{pointer, message: 'must not be null'}];
+	}
+	if (rule.type && !rule.type.includes(valueType) && !(valueType === 'integer' && rule.type.includes('number'))) {
+		return [{pointer, message: 'must be of type ' + rule.type.join(' or ')}];
+	}
+	const errors = [];
+	const fail = (pointer, message) => errors.push({pointer, message});
+	if (rule.enum && !rule.enum.some((enumValue) => JSON.stringify(enumValue) === JSON.stringify(value))) {
+		fail(pointer, 'must be one of ' + JSON.stringify(rule.enum));
+	}
+	if (valueType === 'string') {
+		const length = [...value].length;
+		if (rule.minLength !== undefined && length < rule.minLength) {
+			fail(pointer, 'must be at least ' + rule.minLength + ' characters long');
+		}
+		if (rule.maxLength !== undefined && length > rule.maxLength) {
+			fail(pointer, 'must be at most ' + rule.maxLength + ' characters long');
+		}
+		if (rule.pattern && !new RegExp(rule.pattern).test(value)) {
+			fail(pointer, "must match pattern '" + rule.pattern + "'");
+		}
+		if (rule.format in formatChecks && !formatChecks[rule.format](value)) {
+			fail(pointer, 'must be a valid ' + rule.format);
+		}
+	}
+	if (valueType === 'integer' || valueType === 'number') {
+		if (rule.minimum !== undefined && value < rule.minimum) {
+			fail(pointer, 'must be greater than or equal to ' + rule.minimum);
+		}
+		if (rule.maximum !== undefined && value > rule.maximum) {
+			fail(pointer, 'must be less than or equal to ' + rule.maximum);
+		}
+	}
+	if (valueType === 'array') {
+		if (rule.minItems !== undefined && value.length < rule.minItems) {
+			fail(pointer, 'must contain at least ' + rule.minItems + ' items');
+		}
+		if (rule.maxItems !== undefined && value.length > rule.maxItems) {
+			fail(pointer, 'must contain at most ' + rule.maxItems + ' items');
+		}
+		value.forEach((item, i) => errors.push(...validationErrors(rule.items, item, pointer + '/' + i)));
+	}
+	if (valueType === 'object') {
+		(rule.required || []).filter((key) => !(key in value)).forEach((key) => fail(pointer + '/' + pointerToken(key), 'is required'));
+		Object.keys(value).sort().forEach((key) => {
+			const known = rule.properties && Object.prototype.hasOwnProperty.call(rule.properties, key);
+			if (!known && rule.additionalProperties === false) {
+				fail(pointer + '/' + pointerToken(key), 'is not allowed');
+				return;
+			}
+			errors.push(...validationErrors(known ? rule.properties[key] : undefined, value[key], pointer + '/' + pointerToken(key)));
+		});
+	}
+	(rule.allOf || []).forEach((allOfRule) => errors.push(...validationErrors(allOfRule, value, pointer)));
+	if (rule.anyOf && !rule.anyOf.some((anyOfRule) => validationErrors(anyOfRule, value, pointer).length === 0)) {
+		fail(pointer, 'must match one of the allowed schemas');
+	}
+	if (rule.oneOf && rule.oneOf.filter((oneOfRule) => validationErrors(oneOfRule, value, pointer).length === 0).length !== 1) {
+		fail(pointer, 'must match exactly one of the allowed schemas');
+	}
+	return errors;
+}
+
+function parameterValue(rule, values) {
+	if (!rule || !rule.type) {
+		return values[0];
+	}
+	if (rule.type.includes('array')) {
+		return (values.length === 1 ? values[0].split(',') : values).map((value) => parameterValue(rule.items, [value]));
+	}
+	if ((rule.type.includes('integer') || rule.type.includes('number')) && values[0].trim() !== '' && !isNaN(Number(values[0]))) {
+		return Number(values[0]);
+	}
+	if (rule.type.includes('boolean') && (values[0] === 'true' || values[0] === 'false')) {
+		return values[0] === 'true';
+	}
+	return values[0];
+}
+
+function validateRequest(req, res, validation, params = req.params) {
+	const errors = [];
+	(validation.parameters || []).forEach((parameter) => {
+		const pointer = '/' + parameter.in + '/' + pointerToken(parameter.name);
+		let values = [];
+		if (parameter.in === 'path' && params[parameter.name] !== undefined) {
+			values = [params[parameter.name]];
+		} else if (parameter.in === 'query' && req.query[parameter.name] !== undefined) {
+			values = [].concat(req.query[parameter.name]);
+		} else if (parameter.in === 'header' && req.get(parameter.name) !== undefined) {
+			values = [req.get(parameter.name)];
+		} else if (parameter.in === 'cookie') {
+			const cookie = (req.get('Cookie') || '').split(';').map((pair) => pair.trim().split('=')).find(([name]) => name === parameter.name);
+			values = cookie ? [cookie.slice(1).join('=')] : [];
+		}
+		if (values.length === 0) {
+			if (parameter.required) {
+				errors.push({pointer, message: 'is required'});
+			}
+			return;
+		}
+		errors.push(...validationErrors(parameter.schema, parameterValue(parameter.schema, values), pointer));
+	});
+	if (errors.length > 0) {
+		res.status(400).json({message: 'request validation failed', errors});
+		return false;
+	}
+	if (Number(req.get('Content-Length') || 0) === 0 && req.get('Transfer-Encoding') === undefined) {
+		if (validation.bodyRequired) {
+			res.status(400).json({message: 'request validation failed', errors: [{pointer: '/body', message: 'is required'}]});
+			return false;
+		}
+		return true;
+	}
+	const bodyErrors = validationErrors(validation.body, req.body, '/body');
+	if (bodyErrors.length > 0) {
+		res.status(422).json({message: 'request validation failed', errors: bodyErrors});
+		return false;
+	}
+	return true;
+}
+
+const adminRoutes = %s;
+const initialDb = JSON.stringify(db);
+const journal = [];
+let overrides = [];
+const scenarios = %s;
+const scenarioNames = {};
+Object.values(scenarios).forEach((operationScenarios) => operationScenarios.forEach((scenario) => {
+	scenarioNames[scenario.name] = scenario;
+}));
+let scenarioStates = {};
+let scenarioCalls = {};
+
+function resetScenarios() {
+	scenarioStates = {};
+	scenarioCalls = {};
+	Object.values(scenarioNames).forEach((scenario) => {
+		scenarioStates[scenario.name] = scenario.initialState;
+	});
+}
+resetScenarios();
+
+function takeScenarioResponse(operationId) {
+	let response;
+	(scenarios[operationId] || []).forEach((scenario) => {
+		const state = scenarioStates[scenario.name];
+		if (response === undefined && scenario.responses && state in scenario.responses) {
+			response = Object.assign({}, scenario.responses[state]);
+		}
+		const transition = (scenario.transitions || []).find((candidate) => candidate.from === state);
+		if (transition === undefined) {
+			return;
+		}
+		scenarioCalls[scenario.name] = scenarioCalls[scenario.name] || {};
+		scenarioCalls[scenario.name][operationId] = (scenarioCalls[scenario.name][operationId] || 0) + 1;
+		if (scenarioCalls[scenario.name][operationId] >= Math.max(transition.after || 0, 1)) {
+			scenarioStates[scenario.name] = transition.to;
+			delete scenarioCalls[scenario.name];
+		}
+	});
+	return response;
+}
+
+function matchRoute(method, path) {
+	const segments = path.split('/').filter(Boolean);
+	let match;
+	let matchScore = -1;
+	adminRoutes.filter((route) => route.method === method).forEach((route) => {
+		const pattern = route.path.split('/').filter(Boolean);
+		const score = pattern.filter((segment) => !segment.startsWith(':')).length;
+		if (pattern.length === segments.length && score > matchScore && pattern.every((segment, i) => segment.startsWith(':') || segment === segments[i])) {
+			match = route;
+			matchScore = score;
+		}
+	});
+	return match;
+}
+
+function routeParams(route, path) {
+	const segments = path.split('/').filter(Boolean);
+	const params = {};
+	route.path.split('/').filter(Boolean).forEach((segment, i) => {
+		if (segment.startsWith(':')) {
+			params[segment.slice(1)] = decodeURIComponent(segments[i]);
+		}
+	});
+	return params;
+}
+
+function jsonSubset(expected, actual) {
+	if (expected !== null && typeof expected === 'object') {
+		if (actual === null || typeof actual !== 'object' || Array.isArray(expected) !== Array.isArray(actual) || (Array.isArray(expected) && expected.length !== actual.length)) {
+			return false;
+		}
+		return Object.keys(expected).every((key) => key in actual && jsonSubset(expected[key], actual[key]));
+	}
+	return expected === actual;
+}
+
+function bodyMatches(expected, body) {
+	let actual;
+	try {
+		actual = JSON.parse(body);
+	} catch (err) {
+		return expected === body;
+	}
+	if (typeof expected === 'string') {
+		try {
+			expected = JSON.parse(expected);
+		} catch (err) {
+			return expected === body;
+		}
+	}
+	return jsonSubset(expected, actual);
+}
+
+function matchesRequest(matcher, entry) {
+	if (matcher.method && String(matcher.method).toUpperCase() !== entry.method) {
+		return false;
+	}
+	if (matcher.operationId && matcher.operationId !== entry.operationId) {
+		return false;
+	}
+	if (matcher.path) {
+		const pattern = String(matcher.path).replace(/\{([^}]+)\}/g, ':$1').split('/').filter(Boolean);
+		const segments = entry.path.split('/').filter(Boolean);
+		if (pattern.length !== segments.length || !pattern.every((segment, i) => segment.startsWith(':') || segment === segments[i])) {
+			return false;
+		}
+	}
+	const query = new URLSearchParams(entry.query);
+	if (Object.keys(matcher.query || {}).some((name) => query.get(name) !== String(matcher.query[name]))) {
+		return false;
+	}
+	if (Object.keys(matcher.headers || {}).some((name) => entry.headers[name.toLowerCase()] !== String(matcher.headers[name]))) {
+		return false;
+	}
+	return matcher.body === undefined || matcher.body === null || bodyMatches(matcher.body, entry.body);
+}
+
+function describeMatcher(matcher) {
+	const parts = [matcher.method && String(matcher.method).toUpperCase(), matcher.path, matcher.operationId && 'operationId=' + matcher.operationId].filter(Boolean);
+	return parts.length > 0 ? parts.join(' ') : 'any request';
+}
+
+function harLog(entries) {
+	const nameValues = (values) => Object.keys(values).sort().map((name) => ({name, value: String(values[name])}));
+	return {log: {version: '1.2', creator: {name: 'genmock', version: ''}, entries: entries.map((entry) => {
+		const request = {method: entry.method, url: entry.url, httpVersion: 'HTTP/1.1', cookies: [], headers: nameValues(entry.headers), queryString: [...new URLSearchParams(entry.query)].map(([name, value]) => ({name, value})), headersSize: -1, bodySize: Buffer.byteLength(entry.body)};
+		if (entry.body) {
+			request.postData = {mimeType: entry.headers['content-type'] || '', text: entry.body};
+		}
+		return {
+			startedDateTime: entry.timestamp,
+			time: 0,
+			request,
+			response: {status: entry.status, statusText: require('http').STATUS_CODES[entry.status] || '', httpVersion: 'HTTP/1.1', cookies: [], headers: [], content: {size: 0, mimeType: ''}, redirectURL: '', headersSize: -1, bodySize: -1},
+			cache: {},
+			timings: {send: 0, wait: 0, receive: 0},
+		};
+	})}};
+}
+
+server.use((req, res, next) => {
+	if (!req.path.startsWith('/__admin')) {
+		const route = matchRoute(req.method, req.path);
+		const params = route ? routeParams(route, req.path) : {};
+		const entry = {
+			method: req.method,
+			path: req.path,
+			query: req.originalUrl.split('?').slice(1).join('?'),
+			url: req.protocol + '://' + req.get('host') + req.originalUrl,
+			params: Object.keys(params).length > 0 ? params : undefined,
+			headers: Object.assign({}, req.headers),
+			body: req.body && Object.keys(req.body).length > 0 ? JSON.stringify(req.body) : '',
+			route: route ? route.method + ' ' + route.path : undefined,
+			operationId: route ? route.operationId : undefined,
+			status: 0,
+			timestamp: new Date().toISOString(),
+		};
+		journal.push(entry);
+		res.on('finish', () => {
+			entry.status = res.statusCode;
+		});
+	}
+	next();
+});
+
+server.get('/__admin/routes', (req, res) => {
+	res.json(adminRoutes);
+});
+
+server.get('/__admin/overrides', (req, res) => {
+	res.json(overrides);
+});
+
+server.post('/__admin/overrides', (req, res) => {
+	const override = Object.assign({}, req.body, {method: String(req.body.method || '').toUpperCase(), path: String(req.body.path || '').replace(/\{([^}]+)\}/g, ':$1')});
+	if (!adminRoutes.some((route) => route.method === override.method && route.path === override.path)) {
+		res.status(400).json({message: "unknown route '" + override.method + ' ' + override.path + "', see /__admin/routes for the mocked routes"});
+		return;
+	}
+	if (override.status !== undefined && !(override.status >= 100 && override.status <= 599)) {
+		res.status(400).json({message: 'invalid status ' + override.status});
+		return;
+	}
+	if (override.times !== undefined && !(override.times >= 0)) {
+		res.status(400).json({message: 'invalid times ' + override.times + ', use a positive number of calls'});
+		return;
+	}
+	overrides.push(override);
+	res.status(201).json(override);
+});
+
+server.delete('/__admin/overrides', (req, res) => {
+	overrides = [];
+	res.status(204).end();
+});
+
+server.post('/__admin/reset', (req, res) => {
+	router.db.setState(JSON.parse(initialDb));
+	router.db.write();
+	resetScenarios();
+	res.status(204).end();
+});
+
+server.get('/__admin/scenarios', (req, res) => {
+	res.json(scenarioStates);
+});
+
+server.put('/__admin/scenarios', (req, res) => {
+	const states = req.body || {};
+	const unknown = Object.keys(states).find((name) => !(name in scenarioNames) || !scenarioNames[name].states.includes(states[name]));
+	if (unknown !== undefined) {
+		const message = unknown in scenarioNames ? "unknown state '" + states[unknown] + "' in scenario '" + unknown + "'" : "unknown scenario '" + unknown + "'";
+		res.status(400).json({message});
+		return;
+	}
+	Object.keys(states).forEach((name) => {
+		scenarioStates[name] = states[name];
+		delete scenarioCalls[name];
+	});
+	res.json(scenarioStates);
+});
+
+server.get('/__admin/requests', (req, res) => {
+	res.json(journal.filter((entry) => matchesRequest({method: req.query.method, path: req.query.path, operationId: req.query.operationId}, entry)));
+});
+
+server.post('/__admin/requests/find', (req, res) => {
+	res.json(journal.filter((entry) => matchesRequest(req.body, entry)));
+});
+
+server.post('/__admin/requests/count', (req, res) => {
+	res.json({count: journal.filter((entry) => matchesRequest(req.body, entry)).length});
+});
+
+server.post('/__admin/requests/verify', (req, res) => {
+	const count = journal.filter((entry) => matchesRequest(req.body, entry)).length;
+	const expected = req.body.count;
+	if (expected === undefined ? count > 0 : count === expected) {
+		res.json({count});
+		return;
+	}
+	const message = expected === undefined ?
+		'expected at least one request matching ' + describeMatcher(req.body) + ', got 0' :
+		'expected ' + expected + (expected === 1 ? ' request' : ' requests') + ' matching ' + describeMatcher(req.body) + ', got ' + count;
+	res.status(422).json({message, count});
+});
+
+server.get('/__admin/requests/export', (req, res) => {
+	const format = req.query.format || 'json';
+	if (format !== 'json' && format !== 'har') {
+		res.status(400).json({message: "invalid format '" + format + "', use json or har"});
+		return;
+	}
+	res.type('application/json').send(JSON.stringify(format === 'har' ? harLog(journal) : journal, undefined, 2));
+});
+
+server.delete('/__admin/requests', (req, res) => {
+	journal.length = 0;
+	res.status(204).end();
+});
+
+let chaosOverride = null;
+
+function applyChaos(chaos, req, res, next) {
+	const delayMin = chaos.delayMin || 0;
+	const delay = delayMin + Math.floor(Math.random() * ((chaos.delayMax || 0) - delayMin + 1));
+	setTimeout(() => {
+		if (Math.random() < (chaos.resetRate || 0)) {
+			if (req.socket.resetAndDestroy) {
+				req.socket.resetAndDestroy();
+			} else {
+				req.socket.destroy();
+			}
+			return;
+		}
+		if (Math.random() < (chaos.failureRate || 0)) {
+			const failureCodes = chaos.failureCodes && chaos.failureCodes.length > 0 ? chaos.failureCodes : [500, 502, 503, 504, 429];
+			const failureCode = failureCodes[Math.floor(Math.random() * failureCodes.length)];
+			if (failureCode === 429) {
+				res.set('Retry-After', '1');
+			}
+			res.status(failureCode).json({message: 'injected failure'});
+			return;
+		}
+		if (Math.random() < (chaos.truncateRate || 0)) {
+			res.end = (chunk, encoding) => {
+				const body = Buffer.isBuffer(chunk) ? chunk : Buffer.from(chunk || '', typeof encoding === 'string' ? encoding : 'utf8');
+				if (!res.headersSent) {
+					res.setHeader('Content-Length', body.length);
+				}
+				res.write(body.subarray(0, Math.floor(body.length / 2)), () => res.socket.destroy());
+			};
+		}
+		next();
+	}, delay);
+}
+
+function routeChaos(chaos) {
+	return (req, res, next) => (chaosOverride ? next() : applyChaos(chaos, req, res, next));
+}
+
+server.get('/__admin/chaos', (req, res) => {
+	res.json(chaosOverride);
+});
+
+server.put('/__admin/chaos', (req, res) => {
+	chaosOverride = req.body;
+	res.json(chaosOverride);
+});
+
+server.delete('/__admin/chaos', (req, res) => {
+	chaosOverride = null;
+	res.status(204).end();
+});
+
+server.use((req, res, next) => (chaosOverride && !req.path.startsWith('/__admin') ? applyChaos(chaosOverride, req, res, next) : next()));
+
+server.use((req, res, next) => {
+	const route = req.path.startsWith('/__admin') ? undefined : matchRoute(req.method, req.path);
+	const index = route ? overrides.findIndex((override) => override.method === route.method && override.path === route.path) : -1;
+	if (route && route.validation && (index >= 0 || route.operationId in scenarios) && !validateRequest(req, res, route.validation, routeParams(route, req.path))) {
+		return;
+	}
+	let override = index < 0 ? undefined : Object.assign({}, overrides[index]);
+	if (override && override.times > 0) {
+		overrides[index].times -= 1;
+		if (overrides[index].times === 0) {
+			overrides.splice(index, 1);
+		}
+	}
+	if (override === undefined && route) {
+		override = takeScenarioResponse(route.operationId);
+	}
+	if (override === undefined) {
+		next();
+		return;
+	}
+	const respond = () => {
+		res.status(override.status || Number(route.responseCode) || 200).set(override.headers || {});
+		if ('body' in override) {
+			res.json(override.body);
+		} else {
+			res.end();
+		}
+	};
+	if (route.chaos && !chaosOverride) {
+		applyChaos(route.chaos, req, res, respond);
+	} else {
+		respond();
+	}
+});
+
 server.use(jsonServer.rewriter({
 %s
 }));
 `
-	rewriterTemplate   = `	"%s": "%s",`
-	serverCallTemplate = `
-server.%s('%s', (req, res) => {
-	console.log(%s);
+	rewriterTemplate         = `	"%s": "%s",`
+	variantTemplate          = `nextVariant('%s %s', %s)`
+	preferredExampleTemplate = `preferredExample(req, %s, %s)`
+	selectResponseTemplate   = `[statusCode, responseBody, responseHeaders] = selectResponse(req, '%s', statusCode, responseBody, responseHeaders, %s, %s);`
+	responseHeadersTemplate  = `responseHeaders = %s;`
+	routeChaosTemplate       = `routeChaos(%s), `
+	setResponseHeaders       = `res.set(responseHeaders);`
+	seededResponseHeaders    = `res.set(crudHeaders(responseHeaders, responseBody, '%s'));`
+	serverCallTemplate       = `
+server.%s('%s', %s(req, res) => {
+	console.log(%s);%s
 	statusCode = %s;
 	responseBody = %s;
 	%s
 	res.status(statusCode).json(responseBody);
 });
+`
+	validateRequestTemplate = `
+	if (!validateRequest(req, res, %s)) {
+		return;
+	}`
+	sendContentTemplate = `if (statusCode === %s && sendContent(req, res, statusCode, %s)) {
+		return;
+	}`
+	seededServerCallTemplate = `
+server.%s('%s', %s(req, res) => {
+	console.log(%s);%s
+	responseBody = seededRecords(req, '%s', %s, '%s');
+	statusCode = responseBody === undefined ? 404 : %s;
+	responseBody = responseBody === undefined ? {} : responseBody;%s
+	res.status(statusCode).json(responseBody);
+});
+`
+	crudServerCallTemplate = `
+server.%s('%s', %s(req, res) => {
+	console.log(%s);%s
+	[statusCode, responseBody, responseHeaders] = [null, undefined, %s];%s
+	if (statusCode === null) {
+		[statusCode, responseBody] = crudResponse(req, '%s', '%s', %s, '%s', %s, %s);
+		responseHeaders = statusCode < 300 ? crudHeaders(responseHeaders, responseBody, '%s') : {};
+	}
+	res.set(responseHeaders);
+	if (responseBody === undefined) {
+		res.status(statusCode).end();
+		return;
+	}
+	res.status(statusCode).json(responseBody);
+});
 `
 	endServerTemplateHttp = `
 server.use(middlewares);
@@ -170,276 +869,1533 @@ services:
 `
 )
 
-type RequestStructure struct {
-	Path          string
-	Method        string
-	Body          string
-	DbEntry       string
-	ResponseCode  string
-	ResponseBody  any
-	RequestParams []string
-	RequestBody   any
+type RequestStructure struct {
+	Path             string
+	Method           string
+	Body             string
+	DbEntry          string
+	ResponseCode     string
+	ResponseBody     any
+	ResponseVariants []any
+	ResponseExamples map[string]any
+	RequestParams    []string
+	RequestBody      any
+	DbRecords        []any
+	DbRefs           map[string]string
+	DbSchema         string
+	DbParents        map[string]string
+	Responses        map[string]any
+	ResponseHeaders  map[string]string
+	StatusHeaders    map[string]map[string]string
+	ResponseContent  []ResponseContent
+	Validation       *RequestValidation
+	Chaos            *Chaos
+	OperationId      string
+	Crud             *CrudOperation
+	Scenarios        []RouteScenario
+}
+
+type NameRule struct {
+	Pattern  string
+	Function string
+}
+
+type nameRule struct {
+	pattern *regexp.Regexp
+	info    *gofakeit.Info
+}
+
+type GenerateOptions struct {
+	SpecMajorVersion  int
+	MaxRecursionDepth int
+	GenExamples       bool
+	PreferNull        bool
+	UnionBranch       string
+	AllBranches       bool
+	ExampleName       string
+	MapKeys           int
+	ArrayItems        string
+	NameRules         []NameRule
+	Seed              uint64
+	DbRecords         int
+	AllResponses      bool
+	Validate          bool
+	Chaos             Chaos
+	Stateful          bool
+	Scenarios         []Scenario
+
+	variant      int
+	unionWidth   *int
+	propertyName string
+	nameRules    []nameRule
+	generateErr  *error
+	faker        *gofakeit.Faker
+}
+
+func RandStringBytesRmndr(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		// #nosec G404 // Not really a security risk as it is just to provide example data
+		b[i] = letterBytes[rand.Int64()%int64(len(letterBytes))]
+	}
+	return string(b)
+}
+
+var (
+	pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+	camelCasePattern = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+)
+
+var stringFormats = map[string]func(faker *gofakeit.Faker, length int) string{
+	"date-time": func(faker *gofakeit.Faker, _ int) string {
+		return faker.Date().Format(time.RFC3339)
+	},
+	"date": func(faker *gofakeit.Faker, _ int) string {
+		return faker.Date().Format(time.DateOnly)
+	},
+	"time": func(faker *gofakeit.Faker, _ int) string {
+		return faker.Date().Format(time.TimeOnly)
+	},
+	"duration": func(faker *gofakeit.Faker, _ int) string {
+		return fmt.Sprintf("P%dDT%dH%dM%dS", faker.IntRange(0, 30), faker.IntRange(0, 23), faker.IntRange(0, 59), faker.IntRange(0, 59))
+	},
+	"uuid": func(faker *gofakeit.Faker, _ int) string {
+		return faker.UUID()
+	},
+	"email": func(faker *gofakeit.Faker, _ int) string {
+		return faker.Email()
+	},
+	"uri": func(faker *gofakeit.Faker, _ int) string {
+		return faker.URL()
+	},
+	"iri": func(faker *gofakeit.Faker, _ int) string {
+		return faker.URL()
+	},
+	"hostname": func(faker *gofakeit.Faker, _ int) string {
+		return faker.DomainName()
+	},
+	"ip": func(faker *gofakeit.Faker, _ int) string {
+		return faker.IPv4Address()
+	},
+	"ipv4": func(faker *gofakeit.Faker, _ int) string {
+		return faker.IPv4Address()
+	},
+	"ipv6": func(faker *gofakeit.Faker, _ int) string {
+		return faker.IPv6Address()
+	},
+	"ip-cidr-block": func(faker *gofakeit.Faker, _ int) string {
+		return fmt.Sprintf("%s/%d", faker.IPv4Address(), faker.IntRange(20, 32))
+	},
+	"mac-address": func(faker *gofakeit.Faker, _ int) string {
+		return faker.MacAddress()
+	},
+	"address-or-block-or-range": func(faker *gofakeit.Faker, _ int) string {
+		return faker.IPv4Address()
+	},
+	"byte": func(faker *gofakeit.Faker, length int) string {
+		return base64.StdEncoding.EncodeToString([]byte(faker.LetterN(uint(max(length/4*3, 3)))))
+	},
+	"binary": func(faker *gofakeit.Faker, length int) string {
+		return faker.LetterN(uint(max(length, 1)))
+	},
+	"password": func(faker *gofakeit.Faker, length int) string {
+		return faker.Password(true, true, true, true, false, max(length, 8))
+	},
+}
+
+var defaultNameRules = mustCompileNameRules([]NameRule{
+	{Pattern: `(?i)^(first_?name|given_?name|forename)$`, Function: "firstname"},
+	{Pattern: `(?i)^(last_?name|surname|family_?name)$`, Function: "lastname"},
+	{Pattern: `(?i)^(full_?name|display_?name|author|owner|customer_?name)$`, Function: "name"},
+	{Pattern: `(?i)e_?mail`, Function: "email"},
+	{Pattern: `(?i)(phone|mobile|telephone)`, Function: "phone"},
+	{Pattern: `(?i)(user_?name|login)`, Function: "username"},
+	{Pattern: `(?i)^city$|city_?name`, Function: "city"},
+	{Pattern: `(?i)^(state|province|region)$`, Function: "state"},
+	{Pattern: `(?i)country`, Function: "country"},
+	{Pattern: `(?i)(street|address_?line)`, Function: "street"},
+	{Pattern: `(?i)(zip|postal_?code|post_?code)`, Function: "zip"},
+	{Pattern: `(?i)(company|organi[sz]ation|employer)`, Function: "company"},
+	{Pattern: `(?i)(job_?title|occupation)`, Function: "jobtitle"},
+	{Pattern: `(?i)(url|website|homepage|link)$`, Function: "url"},
+	{Pattern: `(?i)^(colou?r)$`, Function: "color"},
+	{Pattern: `(?i)^currency(_?code)?$`, Function: "currencyshort"},
+	{Pattern: `(?i)^(description|summary|bio|comment|note)s?$`, Function: "sentence"},
+})
+
+func ParseNameRule(value string) (NameRule, error) {
+	separator := strings.LastIndex(value, "=")
+	if separator <= 0 || separator == len(value)-1 {
+		return NameRule{}, fmt.Errorf("invalid name rule '%s', use '<regex>=<gofakeit function>'", value)
+	}
+
+	return NameRule{Pattern: value[:separator], Function: value[separator+1:]}, nil
+}
+
+func compileNameRules(rules []NameRule) ([]nameRule, error) {
+	compiledRules := []nameRule{}
+	for _, rule := range rules {
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern in name rule '%s': %w", rule.Pattern, err)
+		}
+		info := gofakeit.GetFuncLookup(rule.Function)
+		if info == nil {
+			return nil, fmt.Errorf("unknown gofakeit function '%s' in name rule '%s'", rule.Function, rule.Pattern)
+		}
+		compiledRules = append(compiledRules, nameRule{pattern: pattern, info: info})
+	}
+
+	return compiledRules, nil
+}
+
+func mustCompileNameRules(rules []NameRule) []nameRule {
+	compiledRules, err := compileNameRules(rules)
+	if err != nil {
+		panic(err)
+	}
+
+	return compiledRules
+}
+
+func (opts GenerateOptions) fake() *gofakeit.Faker {
+	if opts.faker != nil {
+		return opts.faker
+	}
+
+	return gofakeit.GlobalFaker
+}
+
+func (opts GenerateOptions) fail(err error) {
+	if opts.generateErr != nil && *opts.generateErr == nil {
+		*opts.generateErr = err
+	}
+}
+
+func extensionValue(schema *base.Schema, name string) (any, bool) {
+	if schema.Extensions == nil {
+		return nil, false
+	}
+	node := schema.Extensions.GetOrZero(name)
+	if node == nil {
+		return nil, false
+	}
+
+	return nodeValue(node), true
+}
+
+func fakerLookup(fakerName string) (*gofakeit.Info, error) {
+	normalize := strings.NewReplacer(".", "", "_", "", "-", "", " ", "")
+	if info := gofakeit.GetFuncLookup(normalize.Replace(strings.ToLower(fakerName))); info != nil {
+		return info, nil
+	}
+	category, function, ok := strings.Cut(strings.ToLower(fakerName), ".")
+	function = normalize.Replace(function)
+	if ok {
+		if info := gofakeit.GetFuncLookup(category + function); info != nil {
+			return info, nil
+		}
+		if info := gofakeit.GetFuncLookup(function); info != nil && info.Category == category {
+			return info, nil
+		}
+		if info := gofakeit.GetFuncLookup(category); info != nil && function == "name" {
+			return info, nil
+		}
+		if info := gofakeit.GetFuncLookup(function); info != nil {
+			return info, nil
+		}
+	}
+	fakerNames := slices.Sorted(maps.Keys(gofakeit.FuncLookups))
+	if ok && slices.ContainsFunc(fakerNames, func(name string) bool { return gofakeit.FuncLookups[name].Category == category }) {
+		fakerNames = slices.DeleteFunc(fakerNames, func(name string) bool { return gofakeit.FuncLookups[name].Category != category })
+	} else {
+		function = normalize.Replace(strings.ToLower(fakerName))
+	}
+	slices.SortStableFunc(fakerNames, func(a string, b string) int {
+		return editDistance(function, a) - editDistance(function, b)
+	})
+
+	return nil, fmt.Errorf("unknown faker '%s' in x-faker, did you mean %s?", fakerName, strings.Join(fakerNames[:min(len(fakerNames), 5)], ", "))
+}
+
+func editDistance(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			substitution := previous[j-1]
+			if a[i-1] != b[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous = current
+	}
+
+	return previous[len(b)]
+}
+
+func fakerValue(info *gofakeit.Info, faker *gofakeit.Faker) string {
+	value, err := info.Generate(faker, &gofakeit.MapParams{}, info)
+	if err != nil {
+		return ""
+	}
+	if stringValue, ok := value.(string); ok {
+		return stringValue
+	}
+	valueJson, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+
+	return string(valueJson)
+}
+
+func nameRuleInfo(opts GenerateOptions) *gofakeit.Info {
+	if opts.propertyName == "" {
+		return nil
+	}
+	for _, rule := range slices.Concat(opts.nameRules, defaultNameRules) {
+		if rule.pattern.MatchString(opts.propertyName) {
+			return rule.info
+		}
+	}
+
+	return nil
+}
+
+func stringLengthRange(schema *base.Schema) (int, int) {
+	minLength := 0
+	if schema.MinLength != nil {
+		minLength = int(*schema.MinLength)
+	}
+	maxLength := math.MaxInt
+	if schema.MaxLength != nil {
+		maxLength = max(minLength, int(*schema.MaxLength))
+	}
+
+	return minLength, maxLength
+}
+
+func fitLength(faker *gofakeit.Faker, value string, minLength int, maxLength int) string {
+	runes := []rune(value)
+	if len(runes) > maxLength {
+		return string(runes[:maxLength])
+	}
+	if len(runes) < minLength {
+		return value + faker.LetterN(uint(minLength-len(runes)))
+	}
+
+	return value
+}
+
+func generateExampleData(responseBodyPropertiesSchema *base.Schema, opts GenerateOptions) string {
+	if responseBodyPropertiesSchema.Enum != nil {
+		enumValues := []string{}
+		for _, field := range responseBodyPropertiesSchema.Enum {
+			enumValues = append(enumValues, field.Value)
+		}
+		return opts.fake().RandomString(enumValues)
+	}
+	minLength, maxLength := stringLengthRange(responseBodyPropertiesSchema)
+	length := minLength + opts.fake().IntN(min(maxLength, minLength+14)-minLength+1)
+	if responseBodyPropertiesSchema.Format == "byte" {
+		if length = (length + 3) / 4 * 4; length > maxLength {
+			length -= 4
+		}
+	}
+	generate := func() string {
+		return opts.fake().LetterN(uint(length))
+	}
+	if responseBodyPropertiesSchema.Pattern != "" {
+		generate = func() string {
+			return opts.fake().Regex(responseBodyPropertiesSchema.Pattern)
+		}
+	} else if formatValue, ok := stringFormats[responseBodyPropertiesSchema.Format]; ok {
+		generate = func() string {
+			return formatValue(opts.fake(), length)
+		}
+	} else if info := nameRuleInfo(opts); info != nil {
+		generate = func() string {
+			return fakerValue(info, opts.fake())
+		}
+	}
+	result := generate()
+	for attempt := 0; fitLength(opts.fake(), result, minLength, maxLength) != result && attempt < 100; attempt++ {
+		result = generate()
+	}
+	if fitted := fitLength(opts.fake(), result, minLength, maxLength); validExample(responseBodyPropertiesSchema, fitted) {
+		return fitted
+	}
+
+	return result
+}
+
+func validExample(schema *base.Schema, value string) bool {
+	if schema.Pattern != "" {
+		if pattern, err := regexp.Compile(schema.Pattern); err == nil && !pattern.MatchString(value) {
+			return false
+		}
+	}
+	if schema.Format == "byte" {
+		_, err := base64.StdEncoding.DecodeString(value)
+		return err == nil
+	}
+
+	return validFormat(schema.Format, value)
+}
+
+func roundTo(value float64, decimals int) float64 {
+	pow := math.Pow10(decimals)
+
+	return math.Round(value*pow) / pow
+}
+
+func numberValue(schema *base.Schema, schemaType string, opts GenerateOptions) any {
+	decimals := 2
+	if schemaType == "integer" {
+		decimals = 0
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		multipleOf := strconv.FormatFloat(*schema.MultipleOf, 'f', -1, 64)
+		if _, fraction, ok := strings.Cut(multipleOf, "."); ok {
+			decimals = max(decimals, len(fraction))
+		}
+	}
+	next := func(value float64, direction float64) float64 {
+		switch {
+		case schemaType == "integer":
+			return value + direction
+		case schema.Format == "float":
+			return float64(math.Nextafter32(float32(value), float32(direction)*math.MaxFloat32))
+		case schema.Format == "double":
+			return math.Nextafter(value, direction*math.MaxFloat64)
+		}
+		return value + direction*0.01
+	}
+
+	minimum := 0.0
+	if schema.Minimum != nil {
+		minimum = *schema.Minimum
+	}
+	if schema.ExclusiveMinimum != nil {
+		if schema.ExclusiveMinimum.IsB() && (schema.Minimum == nil || schema.ExclusiveMinimum.B >= minimum) {
+			minimum = next(schema.ExclusiveMinimum.B, 1)
+		} else if schema.ExclusiveMinimum.IsA() && schema.ExclusiveMinimum.A {
+			minimum = next(minimum, 1)
+		}
+	}
+	maximum := 100.0
+	if schema.Maximum != nil {
+		maximum = *schema.Maximum
+	} else if maximum < minimum {
+		maximum = minimum + 100
+	}
+	if schema.ExclusiveMaximum != nil {
+		if schema.ExclusiveMaximum.IsB() && (schema.Maximum == nil || schema.ExclusiveMaximum.B <= maximum) {
+			maximum = next(schema.ExclusiveMaximum.B, -1)
+		} else if schema.ExclusiveMaximum.IsA() && schema.ExclusiveMaximum.A {
+			maximum = next(maximum, -1)
+		}
+	}
+	limit := math.Nextafter(math.MaxInt64, 0)
+	if schemaType == "integer" {
+		if schema.Format == "int32" {
+			limit = math.MaxInt32
+		}
+		minimum = math.Max(math.Ceil(minimum), -limit)
+		maximum = math.Min(math.Floor(maximum), limit)
+	}
+	maximum = math.Max(minimum, maximum)
+
+	value := minimum
+	if opts.GenExamples {
+		if schemaType == "integer" {
+			value = float64(int(minimum) + opts.fake().IntRange(0, int(math.Min(maximum-minimum, limit))))
+		} else {
+			value = opts.fake().Float64Range(minimum, maximum)
+		}
+	}
+	if schema.MultipleOf != nil && *schema.MultipleOf > 0 {
+		multipleOf := *schema.MultipleOf
+		value = math.Round(value/multipleOf) * multipleOf
+		if value < minimum {
+			value += multipleOf
+		}
+		if value > maximum {
+			value -= multipleOf
+		}
+	} else if schemaType == "number" && schema.Format == "float" {
+		value, _ = strconv.ParseFloat(strconv.FormatFloat(value, 'g', -1, 32), 64)
+		return math.Min(math.Max(value, minimum), maximum)
+	} else if schemaType == "number" && schema.Format == "double" {
+		return value
+	}
+	if schemaType == "integer" {
+		return int(value)
+	}
+
+	return roundTo(value, decimals)
+}
+
+func nodeValue(node *yaml.Node) any {
+	var value any
+	if err := node.Decode(&value); err != nil {
+		return node.Value
+	}
+
+	return value
+}
+
+func unionBranch(schema *base.Schema, opts GenerateOptions) *base.SchemaProxy {
+	branches := schema.OneOf
+	if len(branches) == 0 {
+		branches = schema.AnyOf
+	}
+	nonNullBranches := []*base.SchemaProxy{}
+	for _, branch := range branches {
+		branchSchema := branch.Schema()
+		if branchSchema != nil && len(branchSchema.Type) == 1 && branchSchema.Type[0] == "null" {
+			if opts.PreferNull {
+				return branch
+			}
+			continue
+		}
+		nonNullBranches = append(nonNullBranches, branch)
+	}
+	if len(nonNullBranches) == 0 {
+		return nil
+	}
+	if opts.unionWidth != nil && len(nonNullBranches) > *opts.unionWidth {
+		*opts.unionWidth = len(nonNullBranches)
+	}
+
+	index := 0
+	switch opts.UnionBranch {
+	case "", "first":
+	case "random":
+		// #nosec G404 // Not really a security risk as it is just to provide example data
+		index = opts.fake().IntN(len(nonNullBranches))
+	default:
+		index, _ = parseUnionBranch(opts.UnionBranch)
+		if index >= len(nonNullBranches) {
+			opts.fail(fmt.Errorf("union branch %d is out of range, the schema has %d branches", index, len(nonNullBranches)))
+			index = 0
+		}
+	}
+
+	return nonNullBranches[(index+opts.variant)%len(nonNullBranches)]
+}
+
+func parseUnionBranch(unionBranch string) (int, error) {
+	switch unionBranch {
+	case "", "first", "random":
+		return 0, nil
+	}
+	branchIndex, err := strconv.Atoi(unionBranch)
+	if err != nil || branchIndex < 0 {
+		return 0, fmt.Errorf("invalid union branch '%s', use first, random or the index of a branch", unionBranch)
+	}
+
+	return branchIndex, nil
+}
+
+func setDiscriminatorValue(responseBody map[string]any, discriminator *base.Discriminator, branch *base.SchemaProxy) {
+	if discriminator == nil || discriminator.PropertyName == "" || !branch.IsReference() {
+		return
+	}
+	reference := branch.GetReference()
+	discriminatorValue := reference[strings.LastIndex(reference, "/")+1:]
+	if !strings.Contains(reference, "#") {
+		discriminatorValue = strings.TrimSuffix(discriminatorValue, filepath.Ext(discriminatorValue))
+	}
+	for mapping := discriminator.Mapping.First(); mapping != nil; mapping = mapping.Next() {
+		if mapping.Value() == reference || mapping.Value() == discriminatorValue {
+			discriminatorValue = mapping.Key()
+			break
+		}
+	}
+	responseBody[discriminator.PropertyName] = discriminatorValue
+}
+
+func parseItemRange(itemRange string) (int, int, error) {
+	if itemRange == "" {
+		return defaultArrayItems, defaultArrayItems, nil
+	}
+	minValue, maxValue, isRange := strings.Cut(itemRange, "-")
+	if !isRange {
+		maxValue = minValue
+	}
+	minItems, minErr := strconv.Atoi(minValue)
+	maxItems, maxErr := strconv.Atoi(maxValue)
+	if minErr != nil || maxErr != nil || minItems < 0 || maxItems < minItems {
+		return 0, 0, fmt.Errorf("invalid array item range '%s', use a count like '3' or a range like '1-5'", itemRange)
+	}
+
+	return minItems, maxItems, nil
+}
+
+func arrayItemCount(schema *base.Schema, opts GenerateOptions) int {
+	minItems, maxItems, err := parseItemRange(opts.ArrayItems)
+	if err != nil {
+		minItems, maxItems = defaultArrayItems, defaultArrayItems
+	}
+	if schema.MinItems != nil {
+		minItems = max(minItems, int(*schema.MinItems))
+		maxItems = max(maxItems, minItems)
+	}
+	if schema.MaxItems != nil {
+		maxItems = min(maxItems, int(*schema.MaxItems))
+		minItems = min(minItems, maxItems)
+	}
+	if mockCount, ok := extensionValue(schema, "x-mock-count"); ok {
+		count, isInt := mockCount.(int)
+		if isInt && count >= 0 {
+			return count
+		}
+		opts.fail(fmt.Errorf("x-mock-count must be a non-negative integer, got '%v'", mockCount))
+	}
+
+	return minItems + opts.fake().IntN(maxItems-minItems+1)
+}
+
+func arrayItems(schema *base.Schema, opts GenerateOptions, generate func() (any, bool)) []any {
+	count := arrayItemCount(schema, opts)
+	unique := schema.UniqueItems != nil && *schema.UniqueItems
+	seen := map[string]bool{}
+	items := []any{}
+	for attempt := 0; len(items) < count && attempt < count*10; attempt++ {
+		item, ok := generate()
+		if !ok {
+			break
+		}
+		if unique {
+			itemJson, err := json.Marshal(item)
+			if err == nil && seen[string(itemJson)] {
+				continue
+			}
+			seen[string(itemJson)] = true
+		}
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func additionalPropertyKeys(schema *base.Schema, opts GenerateOptions) []string {
+	propertyCount := orderedmap.Len(schema.Properties)
+	count := opts.MapKeys
+	if count <= 0 {
+		count = defaultMapKeys
+	}
+	if schema.MinProperties != nil {
+		count = max(count, int(*schema.MinProperties)-propertyCount)
+	}
+	if schema.MaxProperties != nil {
+		count = min(count, int(*schema.MaxProperties)-propertyCount)
+	}
+	var propertyNames *base.Schema
+	if schema.PropertyNames != nil {
+		propertyNames = schema.PropertyNames.Schema()
+	}
+	seen := map[string]bool{}
+	for key := range schema.Properties.KeysFromOldest() {
+		seen[key] = true
+	}
+	keys := []string{}
+	for attempt := 0; len(keys) < count && attempt < count*10; attempt++ {
+		key := fmt.Sprintf("key%d", attempt+1)
+		switch {
+		case propertyNames == nil:
+		case len(propertyNames.Enum) > 0:
+			key = fmt.Sprint(nodeValue(propertyNames.Enum[attempt%len(propertyNames.Enum)]))
+		case propertyNames.Pattern != "":
+			key = opts.fake().Regex(propertyNames.Pattern)
+		}
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+func additionalPropertyValues(schema *base.Schema, opts GenerateOptions, generate func(*base.SchemaProxy) (any, bool)) map[string]any {
+	values := map[string]any{}
+	if schema.AdditionalProperties == nil || !schema.AdditionalProperties.IsA() || schema.AdditionalProperties.A == nil {
+		return values
+	}
+	for _, key := range additionalPropertyKeys(schema, opts) {
+		if value, ok := generate(schema.AdditionalProperties.A); ok {
+			values[key] = value
+		}
+	}
+
+	return values
+}
+
+func generateVariants(generate func(GenerateOptions) any, opts GenerateOptions) (any, []any) {
+	unionWidth := 0
+	opts.unionWidth = &unionWidth
+	responseBody := generate(opts)
+	if !opts.AllBranches || unionWidth < 2 {
+		return responseBody, nil
+	}
+	variants := []any{responseBody}
+	for variant := 1; variant < unionWidth; variant++ {
+		opts.variant = variant
+		variants = append(variants, generate(opts))
+	}
+
+	return responseBody, variants
+}
+
+func resolveSchemaV3(schema *base.SchemaProxy, definitions *orderedmapv2.OrderedMap[string, *base.SchemaProxy]) *base.Schema {
+	responseBodySchema := schema.Schema()
+	if schema.IsReference() {
+		responseBodyRef, isComponent := strings.CutPrefix(schema.GetReference(), "#/components/schemas/")
+		if isComponent && !strings.Contains(responseBodyRef, "/") {
+			responseBodyContent := definitions.GetPair(responseBodyRef)
+			if responseBodyContent != nil && responseBodyContent.Value != nil {
+				responseBodySchema = responseBodyContent.Value.Schema()
+			}
+		}
+	}
+
+	return responseBodySchema
+}
+
+func schemaTypeV3(schema *base.Schema, opts GenerateOptions) string {
+	nullable := schema.Nullable != nil && *schema.Nullable
+	schemaType := ""
+	for _, typeName := range schema.Type {
+		if typeName == "null" {
+			nullable = true
+		} else if schemaType == "" {
+			schemaType = typeName
+		}
+	}
+	if nullable && (opts.PreferNull || schemaType == "") {
+		return "null"
+	}
+	if schemaType == "" {
+		switch {
+		case len(schema.PrefixItems) > 0 || schema.Items != nil:
+			schemaType = "array"
+		case schema.Properties != nil || schema.AdditionalProperties != nil || len(schema.AllOf) > 0 || schema.If != nil || schema.Else != nil:
+			schemaType = "object"
+		}
+	}
+
+	return schemaType
+}
+
+func fixedValue(schema *base.Schema, opts GenerateOptions) (any, bool) {
+	if schema.Const != nil {
+		return nodeValue(schema.Const), true
+	}
+	if value, ok := extensionValue(schema, "x-mock-value"); ok {
+		return value, true
+	}
+	if fakerName, ok := extensionValue(schema, "x-faker"); ok {
+		info, err := fakerLookup(fmt.Sprint(fakerName))
+		if err != nil {
+			opts.fail(err)
+			return nil, false
+		}
+		value, err := info.Generate(opts.fake(), &gofakeit.MapParams{}, info)
+		if err != nil {
+			opts.fail(fmt.Errorf("faker '%s' in x-faker failed: %w", fakerName, err))
+			return nil, false
+		}
+		return value, true
+	}
+	if opts.GenExamples && schema.Example != nil {
+		return nodeValue(schema.Example), true
+	}
+	if opts.GenExamples && len(schema.Examples) > 0 {
+		return nodeValue(schema.Examples[0]), true
+	}
+	if schema.Default != nil {
+		return nodeValue(schema.Default), true
+	}
+
+	return nil, false
+}
+
+func mediaTypeExampleV3(mediaType *v3high.MediaType, exampleName string) (any, map[string]any, bool) {
+	examples := map[string]any{}
+	var responseBody any
+	for example := mediaType.Examples.First(); example != nil; example = example.Next() {
+		if example.Value() == nil || example.Value().Value == nil {
+			continue
+		}
+		examples[example.Key()] = nodeValue(example.Value().Value)
+		if len(examples) == 1 || example.Key() == exampleName {
+			responseBody = examples[example.Key()]
+		}
+	}
+	if len(examples) > 0 {
+		return responseBody, examples, true
+	}
+	if mediaType.Example != nil {
+		return nodeValue(mediaType.Example), nil, true
+	}
+
+	return nil, nil, false
+}
+
+func conditionalSchemasV3(schema *base.Schema) []*base.SchemaProxy {
+	if schema.If != nil && schema.Then != nil {
+		return []*base.SchemaProxy{schema.If, schema.Then}
+	}
+	if schema.Else != nil {
+		return []*base.SchemaProxy{schema.Else}
+	}
+
+	return nil
+}
+
+func arrayValueV3(arraySchema *base.Schema, definitions *orderedmapv2.OrderedMap[string, *base.SchemaProxy], recursionDepth int, opts GenerateOptions) any {
+	if len(arraySchema.PrefixItems) > 0 {
+		items := []any{}
+		for _, prefixItem := range arraySchema.PrefixItems {
+			prefixItemValue, _ := propertyValueV3(resolveSchemaV3(prefixItem, definitions), definitions, recursionDepth, opts)
+			items = append(items, prefixItemValue)
+		}
+		return items
+	}
+	if arraySchema.Items == nil || !arraySchema.Items.IsA() {
+		return []any{}
+	}
+	arrayItemSchema := resolveSchemaV3(arraySchema.Items.A, definitions)
+
+	return arrayItems(arraySchema, opts, func() (any, bool) {
+		arrayItem, ok := propertyValueV3(arrayItemSchema, definitions, recursionDepth, opts)
+		return arrayItem, ok && arrayItem != nil
+	})
+}
+
+func propertyValueV3(propertySchema *base.Schema, definitions *orderedmapv2.OrderedMap[string, *base.SchemaProxy], recursionDepth int, opts GenerateOptions) (any, bool) {
+	if value, ok := fixedValue(propertySchema, opts); ok {
+		return value, true
+	}
+	schemaType := schemaTypeV3(propertySchema, opts)
+	if schemaType == "" {
+		if branch := unionBranch(propertySchema, opts); branch != nil {
+			branchValue, ok := propertyValueV3(resolveSchemaV3(branch, definitions), definitions, recursionDepth, opts)
+			if branchMap, isMap := branchValue.(map[string]any); isMap {
+				setDiscriminatorValue(branchMap, propertySchema.Discriminator, branch)
+			}
+			return branchValue, ok
+		}
+	}
+	switch schemaType {
+	case "string":
+		if opts.GenExamples {
+			return generateExampleData(propertySchema, opts), true
+		}
+		return "", true
+	case "array":
+		return arrayValueV3(propertySchema, definitions, recursionDepth, opts), true
+	case "integer", "number":
+		return numberValue(propertySchema, schemaType, opts), true
+	case "boolean":
+		return false, true
+	case "object":
+		return schemaToPropertyMapV3(propertySchema.ParentProxy, definitions, map[string]any{}, recursionDepth+1, opts), true
+	case "":
+		return nil, false
+	default:
+		return nil, true
+	}
+}
+
+func schemaToPropertyMapV3(schema *base.SchemaProxy, definitions *orderedmapv2.OrderedMap[string, *base.SchemaProxy], responseBody any, recursionDepth int, opts GenerateOptions) any {
+	if recursionDepth > opts.MaxRecursionDepth {
+		return nil
+	}
+	responseBodySchema := resolveSchemaV3(schema, definitions)
+	if responseBodySchema == nil {
+		return nil
+	}
+	if value, ok := fixedValue(responseBodySchema, opts); ok {
+		return value
+	}
+	schemaType := schemaTypeV3(responseBodySchema, opts)
+	if len(responseBodySchema.AllOf) > 0 {
+		if _, ok := responseBody.(map[string]any); !ok {
+			responseBody = map[string]any{}
+		}
+		for _, schemaField := range responseBodySchema.AllOf {
+			responseBodySub := schemaToPropertyMapV3(schemaField, definitions, responseBody, recursionDepth, opts)
+			if responseBodySubMap, ok := responseBodySub.(map[string]any); ok {
+				for k, v := range responseBodySubMap {
+					responseBody.(map[string]any)[k] = v
+				}
+			}
+		}
+	} else if schemaType == "array" {
+		responseBody = arrayValueV3(responseBodySchema, definitions, recursionDepth, opts)
+	} else if schemaType == "null" {
+		return nil
+	} else {
+		responseBody = map[string]any{}
+	}
+	responseBodyMap, ok := responseBody.(map[string]any)
+	if !ok {
+		return responseBody
+	}
+	for responseBodyProperties := responseBodySchema.Properties.First(); responseBodyProperties != nil; responseBodyProperties = responseBodyProperties.Next() {
+		propertyOpts := opts
+		propertyOpts.propertyName = responseBodyProperties.Key()
+		propertyValue, ok := propertyValueV3(responseBodyProperties.Value().Schema(), definitions, recursionDepth, propertyOpts)
+		if ok {
+			responseBodyMap[responseBodyProperties.Key()] = propertyValue
+		}
+	}
+	maps.Copy(responseBodyMap, additionalPropertyValues(responseBodySchema, opts, func(valueSchema *base.SchemaProxy) (any, bool) {
+		return propertyValueV3(resolveSchemaV3(valueSchema, definitions), definitions, recursionDepth, opts)
+	}))
+	if branch := unionBranch(responseBodySchema, opts); branch != nil {
+		branchBody := schemaToPropertyMapV3(branch, definitions, nil, recursionDepth, opts)
+		branchBodyMap, ok := branchBody.(map[string]any)
+		if !ok && len(responseBodyMap) == 0 {
+			return branchBody
+		}
+		maps.Copy(responseBodyMap, branchBodyMap)
+		setDiscriminatorValue(responseBodyMap, responseBodySchema.Discriminator, branch)
+	}
+	for _, conditionalSchema := range conditionalSchemasV3(responseBodySchema) {
+		conditionalBody := schemaToPropertyMapV3(conditionalSchema, definitions, map[string]any{}, recursionDepth, opts)
+		if conditionalBodyMap, ok := conditionalBody.(map[string]any); ok {
+			maps.Copy(responseBodyMap, conditionalBodyMap)
+		}
+	}
+
+	return responseBodyMap
+}
+
+func itemValueV2(itemSchema *base.SchemaProxy, definitions *orderedmap.Map[string, *base.SchemaProxy], recursionDepth int, opts GenerateOptions) (any, bool) {
+	if itemValue, ok := propertyValueV2(itemSchema.Schema(), definitions, recursionDepth, opts); ok && itemValue != nil {
+		return itemValue, true
+	}
+	itemValue := schemaToPropertyMapV2(itemSchema, definitions, nil, recursionDepth+1, opts)
+
+	return itemValue, itemValue != nil
+}
+
+func arrayValueV2(arraySchema *base.Schema, definitions *orderedmap.Map[string, *base.SchemaProxy], recursionDepth int, opts GenerateOptions) []any {
+	if arraySchema.Items == nil || !arraySchema.Items.IsA() {
+		return []any{}
+	}
+
+	return arrayItems(arraySchema, opts, func() (any, bool) {
+		return itemValueV2(arraySchema.Items.A, definitions, recursionDepth, opts)
+	})
+}
+
+func propertyValueV2(propertySchema *base.Schema, definitions *orderedmap.Map[string, *base.SchemaProxy], recursionDepth int, opts GenerateOptions) (any, bool) {
+	if value, ok := fixedValue(propertySchema, opts); ok {
+		return value, true
+	}
+	if propertySchema.Type == nil {
+		return nil, false
+	}
+	switch propertySchema.Type[0] {
+	case "string":
+		if opts.GenExamples {
+			return generateExampleData(propertySchema, opts), true
+		}
+		return "", true
+	case "array":
+		return arrayValueV2(propertySchema, definitions, recursionDepth, opts), true
+	case "integer", "number":
+		return numberValue(propertySchema, propertySchema.Type[0], opts), true
+	case "boolean":
+		return false, true
+	default:
+		return nil, true
+	}
+}
+
+func schemaToPropertyMapV2(schema *base.SchemaProxy, definitions *orderedmap.Map[string, *base.SchemaProxy], responseBody any, recursionDepth int, opts GenerateOptions) any {
+	if recursionDepth > opts.MaxRecursionDepth {
+		return nil
+	}
+	responseBodySchema := schema.Schema()
+	if schema.IsReference() {
+		responseBodyRef, isDefinition := strings.CutPrefix(schema.GetReference(), "#/definitions/")
+		if isDefinition && !strings.Contains(responseBodyRef, "/") {
+			responseBodyContent := definitions.GetPair(responseBodyRef)
+			if responseBodyContent != nil && responseBodyContent.Value != nil {
+				responseBodySchema = responseBodyContent.Value.Schema()
+			}
+		}
+	}
+	if value, ok := fixedValue(responseBodySchema, opts); ok {
+		return value
+	}
+	if responseBodySchema.AllOf != nil {
+		if _, ok := responseBody.(map[string]any); !ok {
+			responseBody = map[string]any{}
+		}
+		for _, schemaField := range responseBodySchema.AllOf {
+			responseBodySub := schemaToPropertyMapV2(schemaField, definitions, responseBody, recursionDepth, opts)
+			if responseBodySubMap, ok := responseBodySub.(map[string]any); ok {
+				for k, v := range responseBodySubMap {
+					responseBody.(map[string]any)[k] = v
+				}
+			}
+		}
+	}
+	if responseBodySchema.Type != nil && responseBodySchema.Type[0] == "array" {
+		responseBody = arrayValueV2(responseBodySchema, definitions, recursionDepth, opts)
+	} else {
+		responseBody = map[string]any{}
+	}
+	for responseBodyProperties := responseBodySchema.Properties.First(); responseBodyProperties != nil; responseBodyProperties = responseBodyProperties.Next() {
+		responseBodyPropertiesSchema := responseBodyProperties.Value().Schema()
+		propertyOpts := opts
+		propertyOpts.propertyName = responseBodyProperties.Key()
+		if propertyValue, ok := propertyValueV2(responseBodyPropertiesSchema, definitions, recursionDepth, propertyOpts); ok {
+			responseBody.(map[string]any)[responseBodyProperties.Key()] = propertyValue
+		} else {
+			responseBody = schemaToPropertyMapV2(responseBodyPropertiesSchema.ParentProxy, definitions, responseBody, recursionDepth, opts)
+		}
+	}
+	if responseBodyMap, ok := responseBody.(map[string]any); ok {
+		maps.Copy(responseBodyMap, additionalPropertyValues(responseBodySchema, opts, func(valueSchema *base.SchemaProxy) (any, bool) {
+			return itemValueV2(valueSchema, definitions, recursionDepth, opts)
+		}))
+	}
+	if branch := unionBranch(responseBodySchema, opts); branch != nil {
+		if responseBodyMap, ok := responseBody.(map[string]any); ok {
+			responseBodySub := schemaToPropertyMapV2(branch, definitions, nil, recursionDepth, opts)
+			if responseBodySubMap, ok := responseBodySub.(map[string]any); ok {
+				maps.Copy(responseBodyMap, responseBodySubMap)
+			}
+			setDiscriminatorValue(responseBodyMap, responseBodySchema.Discriminator, branch)
+		}
+	}
+
+	return responseBody
+}
+
+func responseBodyV3(response *v3high.Response, definitions *orderedmapv2.OrderedMap[string, *base.SchemaProxy], opts GenerateOptions) any {
+	if response == nil || orderedmap.Len(response.Content) == 0 {
+		return nil
+	}
+	mediaType := jsonMediaTypeV3(response.Content)
+	if opts.GenExamples {
+		if exampleBody, _, ok := mediaTypeExampleV3(mediaType, opts.ExampleName); ok {
+			return exampleBody
+		}
+	}
+	if mediaType.Schema == nil {
+		return nil
+	}
+
+	return schemaToPropertyMapV3(mediaType.Schema, definitions, nil, 0, opts)
+}
+
+func responsesV3(responses *v3high.Responses, pathName string, definitions *orderedmapv2.OrderedMap[string, *base.SchemaProxy], opts GenerateOptions) (map[string]any, map[string]map[string]string) {
+	statusResponses := map[string]any{}
+	var statusHeaders map[string]map[string]string
+	addResponse := func(key string, response *v3high.Response) {
+		statusResponses[key] = responseBodyV3(response, definitions, opts)
+		if headers := responseHeadersV3(response, statusResponses[key], pathName, definitions, opts); headers != nil {
+			if statusHeaders == nil {
+				statusHeaders = map[string]map[string]string{}
+			}
+			statusHeaders[key] = headers
+		}
+	}
+	for responseCodes := responses.Codes.First(); responseCodes != nil; responseCodes = responseCodes.Next() {
+		addResponse(responseCodes.Key(), responseCodes.Value())
+	}
+	if responses.Default != nil {
+		addResponse("default", responses.Default)
+	}
+
+	return statusResponses, statusHeaders
+}
+
+func responseBodyV2(response *v2high.Response, definitions *orderedmap.Map[string, *base.SchemaProxy], opts GenerateOptions) any {
+	if response == nil {
+		return nil
+	}
+	if opts.GenExamples && response.Examples != nil && response.Examples.Values.Len() > 0 {
+		return nodeValue(response.Examples.Values.First().Value())
+	}
+	if response.Schema == nil {
+		return nil
+	}
+
+	return schemaToPropertyMapV2(response.Schema, definitions, nil, 0, opts)
+}
+
+func responsesV2(responses *v2high.Responses, pathName string, definitions *orderedmap.Map[string, *base.SchemaProxy], opts GenerateOptions) (map[string]any, map[string]map[string]string) {
+	statusResponses := map[string]any{}
+	var statusHeaders map[string]map[string]string
+	addResponse := func(key string, response *v2high.Response) {
+		statusResponses[key] = responseBodyV2(response, definitions, opts)
+		if headers := responseHeadersV2(response, statusResponses[key], pathName, opts); headers != nil {
+			if statusHeaders == nil {
+				statusHeaders = map[string]map[string]string{}
+			}
+			statusHeaders[key] = headers
+		}
+	}
+	for responseCodes := responses.Codes.First(); responseCodes != nil; responseCodes = responseCodes.Next() {
+		addResponse(responseCodes.Key(), responseCodes.Value())
+	}
+	if responses.Default != nil {
+		addResponse("default", responses.Default)
+	}
+
+	return statusResponses, statusHeaders
+}
+
+func headerValue(name string, value any, responseBody any, pathName string) string {
+	switch strings.ToLower(name) {
+	case "etag":
+		if responseBody != nil {
+			bodyJson, err := json.Marshal(responseBody)
+			if err == nil {
+				bodyHash := fnv.New64a()
+				_, _ = bodyHash.Write(bodyJson)
+				return fmt.Sprintf("\"%x\"", bodyHash.Sum64())
+			}
+		}
+	case "x-total-count":
+		if items, ok := responseBody.([]any); ok {
+			return strconv.Itoa(len(items))
+		}
+	case "location":
+		if record, ok := responseBody.(map[string]any); ok && record["id"] != nil && !strings.Contains(pathName, ":") {
+			return fmt.Sprintf("%s/%v", strings.TrimSuffix(pathName, "/"), record["id"])
+		}
+	}
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typedValue
+	case []any:
+		items := make([]string, len(typedValue))
+		for i, item := range typedValue {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	case map[string]any:
+		valueJson, _ := json.Marshal(typedValue)
+		return string(valueJson)
+	default:
+		return fmt.Sprint(typedValue)
+	}
+}
+
+func responseHeadersV3(response *v3high.Response, responseBody any, pathName string, definitions *orderedmapv2.OrderedMap[string, *base.SchemaProxy], opts GenerateOptions) map[string]string {
+	if response == nil || orderedmap.Len(response.Headers) == 0 {
+		return nil
+	}
+	headers := map[string]string{}
+	for name, header := range response.Headers.FromOldest() {
+		headerOpts := opts
+		headerOpts.propertyName = name
+		var value any
+		if opts.GenExamples && header.Example != nil {
+			value = nodeValue(header.Example)
+		} else if opts.GenExamples && orderedmap.Len(header.Examples) > 0 {
+			value = nodeValue(header.Examples.First().Value().Value)
+		} else if header.Schema != nil {
+			value, _ = propertyValueV3(resolveSchemaV3(header.Schema, definitions), definitions, 0, headerOpts)
+		}
+		headers[name] = headerValue(name, value, responseBody, pathName)
+	}
+
+	return headers
+}
+
+func responseHeadersV2(response *v2high.Response, responseBody any, pathName string, opts GenerateOptions) map[string]string {
+	if response == nil || orderedmap.Len(response.Headers) == 0 {
+		return nil
+	}
+	headers := map[string]string{}
+	for name, header := range response.Headers.FromOldest() {
+		headerOpts := opts
+		headerOpts.propertyName = name
+		value := header.Default
+		if value == nil && len(header.Enum) > 0 {
+			value = header.Enum[0]
+		}
+		if node, ok := value.(*yaml.Node); ok {
+			value = nodeValue(node)
+		}
+		if value == nil {
+			headerSchema := &base.Schema{Type: []string{header.Type}, Format: header.Format, Pattern: header.Pattern}
+			if header.Minimum != 0 {
+				headerSchema.Minimum = new(float64(header.Minimum))
+			}
+			if header.Maximum != 0 {
+				headerSchema.Maximum = new(float64(header.Maximum))
+			}
+			if header.MinLength > 0 {
+				headerSchema.MinLength = new(int64(header.MinLength))
+			}
+			if header.MaxLength > 0 {
+				headerSchema.MaxLength = new(int64(header.MaxLength))
+			}
+			if header.Items != nil {
+				headerSchema.Items = &base.DynamicValue[*base.SchemaProxy, bool]{A: base.CreateSchemaProxy(&base.Schema{Type: []string{header.Items.Type}, Format: header.Items.Format})}
+			}
+			value, _ = propertyValueV2(headerSchema, nil, 0, headerOpts)
+		}
+		headers[name] = headerValue(name, value, responseBody, pathName)
+	}
+
+	return headers
+}
+
+func pathDbEntry(specPath string) string {
+	dbEntry := strings.ReplaceAll(pathParamPattern.ReplaceAllString(specPath, ""), "/", "-")
+	dbEntry = strings.ReplaceAll(dbEntry, "--", "-")
+	dbEntry = strings.Split(dbEntry, "?")[0]
+	if len(dbEntry) > 0 && dbEntry[0] == '-' {
+		dbEntry = dbEntry[1:]
+	}
+	if len(dbEntry) > 0 && dbEntry[len(dbEntry)-1] == '-' {
+		dbEntry = dbEntry[:len(dbEntry)-1]
+	}
+
+	return dbEntry
+}
+
+func itemParam(pathName string, requestParams []string) string {
+	if len(requestParams) == 0 || !strings.HasSuffix(pathName, ":"+requestParams[len(requestParams)-1]) {
+		return ""
+	}
+
+	return requestParams[len(requestParams)-1]
+}
+
+func dbRecords(recordSchema *base.Schema, idParam string, opts GenerateOptions, generate func() any) ([]any, map[string]string) {
+	records := []any{}
+	for i := range opts.DbRecords {
+		record, ok := generate().(map[string]any)
+		if !ok {
+			return nil, nil
+		}
+		id := any(i + 1)
+		if _, isString := record["id"].(string); isString {
+			id = strconv.Itoa(i + 1)
+		}
+		record["id"] = id
+		if _, ok := record[idParam]; ok && idParam != "id" {
+			record[idParam] = id
+		}
+		records = append(records, record)
+	}
+
+	return records, mockRefs(recordSchema)
+}
+
+func dbRecordsV3(responseSchema *base.SchemaProxy, definitions *orderedmapv2.OrderedMap[string, *base.SchemaProxy], idParam string, opts GenerateOptions) ([]any, map[string]string, string) {
+	recordSchema := responseSchema
+	if schema := resolveSchemaV3(responseSchema, definitions); schema != nil && schemaTypeV3(schema, opts) == "array" {
+		if schema.Items == nil || !schema.Items.IsA() {
+			return nil, nil, ""
+		}
+		recordSchema = schema.Items.A
+	}
+	records, refs := dbRecords(resolveSchemaV3(recordSchema, definitions), idParam, opts, func() any {
+		return schemaToPropertyMapV3(recordSchema, definitions, nil, 0, opts)
+	})
+
+	return records, refs, recordSchema.GetReference()
+}
+
+func dbRecordsV2(responseSchema *base.SchemaProxy, definitions *orderedmap.Map[string, *base.SchemaProxy], idParam string, opts GenerateOptions) ([]any, map[string]string, string) {
+	recordSchema := responseSchema
+	if schema := responseSchema.Schema(); schema != nil && len(schema.Type) > 0 && schema.Type[0] == "array" {
+		if schema.Items == nil || !schema.Items.IsA() {
+			return nil, nil, ""
+		}
+		recordSchema = schema.Items.A
+	}
+	records, refs := dbRecords(recordSchema.Schema(), idParam, opts, func() any {
+		return schemaToPropertyMapV2(recordSchema, definitions, nil, 0, opts)
+	})
+
+	return records, refs, recordSchema.GetReference()
+}
+
+func mockRefs(recordSchema *base.Schema) map[string]string {
+	if recordSchema == nil {
+		return nil
+	}
+	refs := map[string]string{}
+	for key, propertySchema := range recordSchema.Properties.FromOldest() {
+		if propertySchema.Schema() == nil {
+			continue
+		}
+		if ref, ok := extensionValue(propertySchema.Schema(), "x-mock-ref"); ok {
+			refs[key] = pathDbEntry(fmt.Sprint(ref))
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	return refs
+}
+
+func linkRefsV3(links *orderedmap.Map[string, *v3high.Link], operationEntries map[string]string) map[string]string {
+	refs := map[string]string{}
+	for _, link := range links.FromOldest() {
+		collection := operationEntries[link.OperationId]
+		if operationPath, ok := strings.CutPrefix(link.OperationRef, "#/paths/"); ok {
+			operationPath, _, _ = strings.Cut(operationPath, "/")
+			collection = pathDbEntry(strings.NewReplacer("~1", "/", "~0", "~").Replace(operationPath))
+		}
+		if collection == "" {
+			continue
+		}
+		for _, expression := range link.Parameters.FromOldest() {
+			if property, ok := strings.CutPrefix(expression, "$response.body#/"); ok && !strings.Contains(property, "/") {
+				refs[property] = collection
+			}
+		}
+	}
+
+	return refs
 }
 
-func RandStringBytesRmndr(n int) string {
-	b := make([]byte, n)
-	for i := range b {
-		// #nosec G404 // Not really a security risk as it is just to provide example data
-		b[i] = letterBytes[rand.Int64()%int64(len(letterBytes))]
+func dbParents(specPath string, itemParam string) map[string]string {
+	parents := map[string]string{}
+	for _, param := range pathParamPattern.FindAllStringSubmatch(specPath, -1) {
+		requestParam := strings.ReplaceAll(param[1], "-", "")
+		if requestParam == itemParam {
+			continue
+		}
+		parents[requestParam] = pathDbEntry(specPath[:strings.Index(specPath, param[0])])
 	}
-	return string(b)
+	if len(parents) == 0 {
+		return nil
+	}
+
+	return parents
 }
 
-func generateExampleData(responseBodyPropertiesSchema *base.Schema) string {
-	// #nosec G404 // Not really a security risk as it is just to provide example data
-	result := RandStringBytesRmndr(rand.IntN(15))
-	enumValues := []string{}
-	if responseBodyPropertiesSchema.Enum != nil {
-		for _, field := range responseBodyPropertiesSchema.Enum {
-			enumValues = append(enumValues, field.Value)
-		}
-		result = gofakeit.RandomString(enumValues)
+func parentField(param string, collection string) string {
+	if param != "id" {
+		return param
 	}
-	if responseBodyPropertiesSchema.Format != "" {
-		switch responseBodyPropertiesSchema.Format {
-		case "date-time":
-			result = gofakeit.Date().String()
-		case "uuid":
-			result = gofakeit.UUID()
-		case "ip":
-			result = gofakeit.IPv4Address()
-		case "ip-cidr-block":
-			result = fmt.Sprintf("%s/%d", gofakeit.IPv4Address(), gofakeit.IntRange(20, 32))
-		case "mac-address":
-			result = gofakeit.MacAddress()
-		case "address-or-block-or-range":
-			result = gofakeit.IPv4Address()
-		}
+	segments := strings.Split(collection, "-")
+
+	return fmt.Sprintf("%sId", singular(segments[len(segments)-1]))
+}
+
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies"):
+		return strings.TrimSuffix(name, "ies") + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return strings.TrimSuffix(name, "es")
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return strings.TrimSuffix(name, "s")
 	}
 
-	return result
+	return name
 }
 
-func schemaToPropertyMapV3(schema *base.SchemaProxy, definitions *orderedmapv2.OrderedMap[string, *base.SchemaProxy], responseBody any, maxRecursion int, recursionDepth int, genExamples bool) any {
-	if recursionDepth > maxRecursion {
-		return nil
+func foreignKeyCollection(key string, collections []string) string {
+	entity := ""
+	switch {
+	case strings.HasSuffix(key, "_id"):
+		entity = strings.TrimSuffix(key, "_id")
+	case strings.HasSuffix(key, "Id"), strings.HasSuffix(key, "ID"):
+		entity = key[:len(key)-2]
 	}
-	responseBodySchema := schema.Schema()
-	if schema.IsReference() {
-		refSplit := strings.Split(schema.GetReference(), "/")
-		responseBodyRef := refSplit[len(refSplit)-1]
-		responseBodyContent := definitions.GetPair(responseBodyRef)
-		if responseBodyContent.Value != nil {
-			responseBodySchema = responseBodyContent.Value.Schema()
-		}
+	if entity == "" {
+		return ""
 	}
-	if len(responseBodySchema.AllOf) > 0 {
-		if _, ok := responseBody.(map[string]any); !ok {
-			responseBody = map[string]any{}
-		}
-		for _, schemaField := range responseBodySchema.AllOf {
-			responseBodySub := schemaToPropertyMapV3(schemaField, definitions, responseBody, maxRecursion, recursionDepth, genExamples)
-			if responseBodySubMap, ok := responseBodySub.(map[string]any); ok {
-				for k, v := range responseBodySubMap {
-					responseBody.(map[string]any)[k] = v
-				}
-			}
-		}
-	} else if responseBodySchema.Type != nil && responseBodySchema.Type[0] == "array" {
-		items := []map[string]any{}
-		if responseBodySchema.Items != nil && responseBodySchema.Items.IsA() {
-			arrayItemSchema := responseBodySchema.Items.A
-			var arrayItem any
-			arrayItem = schemaToPropertyMapV3(arrayItemSchema, definitions, arrayItem, maxRecursion, recursionDepth+1, genExamples)
-			if arrayItem != nil {
-				items = []map[string]any{arrayItem.(map[string]any)}
-			}
-		}
-		if len(items) > 0 {
-			responseBody = items
-		} else {
-			responseBody = []any{}
+	entity = strings.ToLower(strings.ReplaceAll(camelCasePattern.ReplaceAllString(entity, "$1-$2"), "_", "-"))
+	matches := func(name string) bool {
+		return name == entity || singular(name) == entity
+	}
+	for _, collection := range collections {
+		if matches(collection) {
+			return collection
 		}
-	} else {
-		responseBody = map[string]any{}
 	}
-	for responseBodyProperties := responseBodySchema.Properties.First(); responseBodyProperties != nil; responseBodyProperties = responseBodyProperties.Next() {
-		responseBodyPropertiesSchema := responseBodyProperties.Value().Schema()
-		if responseBodyPropertiesSchema.Type != nil {
-			switch responseBodyPropertiesSchema.Type[0] {
-			case "string":
-				responseBody.(map[string]any)[responseBodyProperties.Key()] = ""
-				if genExamples {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = generateExampleData(responseBodyPropertiesSchema)
-				}
-			case "array":
-				items := []map[string]any{}
-				if responseBodyPropertiesSchema.Items != nil && responseBodyPropertiesSchema.Items.IsA() {
-					arrayItemSchema := responseBodyPropertiesSchema.Items.A
-					var arrayItem any
-					arrayItem = schemaToPropertyMapV3(arrayItemSchema, definitions, arrayItem, maxRecursion, recursionDepth+1, genExamples)
-					if arrayItem != nil {
-						items = []map[string]any{arrayItem.(map[string]any)}
-					}
-				}
-				if len(items) > 0 {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = items
-				} else {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = []any{}
-				}
-			case "integer":
-				minimum := 0
-				maximum := 100
-				if responseBodyPropertiesSchema.Minimum != nil {
-					minimum = int(*responseBodyPropertiesSchema.Minimum)
-				}
-				if responseBodyPropertiesSchema.Maximum != nil {
-					maximum = int(*responseBodyPropertiesSchema.Maximum)
-				}
-				if responseBodyPropertiesSchema.Default != nil {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = responseBodyPropertiesSchema.Default.Value
-				} else {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = minimum
-				}
-				if genExamples {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = gofakeit.IntRange(minimum, maximum)
-				}
-			case "boolean":
-				responseBody.(map[string]any)[responseBodyProperties.Key()] = false
-			case "object":
-				responseBody.(map[string]any)[responseBodyProperties.Key()] = map[string]any{}
-				responseBody.(map[string]any)[responseBodyProperties.Key()] = schemaToPropertyMapV3(responseBodyPropertiesSchema.ParentProxy, definitions, responseBody.(map[string]any)[responseBodyProperties.Key()].(map[string]any), maxRecursion, recursionDepth+1, genExamples)
-			default:
-				responseBody.(map[string]any)[responseBodyProperties.Key()] = nil
+	for _, collection := range collections {
+		segments := strings.Split(collection, "-")
+		for i := 1; i < len(segments); i++ {
+			if matches(strings.Join(segments[i:], "-")) {
+				return collection
 			}
 		}
 	}
 
-	return responseBody
+	return ""
 }
 
-func schemaToPropertyMapV2(schema *base.SchemaProxy, definitions *orderedmap.Map[string, *base.SchemaProxy], responseBody any, maxRecursion int, recursionDepth int, genExamples bool) any {
-	if recursionDepth > maxRecursion {
-		return nil
+func foreignKeyValue(current any, id any) any {
+	if _, isString := current.(string); isString {
+		return fmt.Sprint(id)
 	}
-	responseBodySchema := schema.Schema()
-	if schema.IsReference() {
-		refSplit := strings.Split(schema.GetReference(), "/")
-		responseBodyRef := refSplit[len(refSplit)-1]
-		responseBodyContent := definitions.GetPair(responseBodyRef)
-		if responseBodyContent.Value != nil {
-			responseBodySchema = responseBodyContent.Value.Schema()
+
+	return id
+}
+
+func linkDbRecords(dbEntryMap map[string][]any, refs map[string]map[string]string, parents map[string]map[string]string) {
+	collections := []string{}
+	for _, collection := range slices.Sorted(maps.Keys(dbEntryMap)) {
+		if len(dbEntryMap[collection]) > 0 {
+			collections = append(collections, collection)
 		}
 	}
-	if responseBodySchema.AllOf != nil {
-		if _, ok := responseBody.(map[string]any); !ok {
-			responseBody = map[string]any{}
-		}
-		for _, schemaField := range responseBodySchema.AllOf {
-			responseBodySub := schemaToPropertyMapV2(schemaField, definitions, responseBody, maxRecursion, recursionDepth, genExamples)
-			if responseBodySubMap, ok := responseBodySub.(map[string]any); ok {
-				for k, v := range responseBodySubMap {
-					responseBody.(map[string]any)[k] = v
-				}
+	recordIds := func(collection string) []any {
+		ids := []any{}
+		for _, record := range dbEntryMap[collection] {
+			if recordMap, ok := record.(map[string]any); ok && recordMap["id"] != nil {
+				ids = append(ids, recordMap["id"])
 			}
 		}
+		return ids
 	}
-	if responseBodySchema.Type != nil && responseBodySchema.Type[0] == "array" {
-		items := []map[string]any{}
-		if responseBodySchema.Items != nil && responseBodySchema.Items.IsA() {
-			arrayItemSchema := responseBodySchema.Items.A
-			var arrayItem any
-			arrayItem = schemaToPropertyMapV2(arrayItemSchema, definitions, arrayItem, maxRecursion, recursionDepth+1, genExamples)
-			if arrayItem != nil {
-				items = []map[string]any{arrayItem.(map[string]any)}
+	for _, collection := range collections {
+		for i, record := range dbEntryMap[collection] {
+			recordMap, ok := record.(map[string]any)
+			if !ok {
+				continue
 			}
-		}
-		if len(items) > 0 {
-			responseBody = items
-		} else {
-			responseBody = []any{}
-		}
-	} else {
-		responseBody = map[string]any{}
-	}
-	for responseBodyProperties := responseBodySchema.Properties.First(); responseBodyProperties != nil; responseBodyProperties = responseBodyProperties.Next() {
-		responseBodyPropertiesSchema := responseBodyProperties.Value().Schema()
-		if responseBodyPropertiesSchema.Type != nil {
-			switch responseBodyPropertiesSchema.Type[0] {
-			case "string":
-				responseBody.(map[string]any)[responseBodyProperties.Key()] = ""
-				if genExamples {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = generateExampleData(responseBodyPropertiesSchema)
-				}
-			case "array":
-				items := []map[string]any{}
-				if responseBodyPropertiesSchema.Items != nil && responseBodyPropertiesSchema.Items.IsA() {
-					arrayItemSchema := responseBodyPropertiesSchema.Items.A
-					var arrayItem any
-					arrayItem = schemaToPropertyMapV2(arrayItemSchema, definitions, arrayItem, maxRecursion, recursionDepth+1, genExamples)
-					if arrayItem != nil {
-						items = []map[string]any{arrayItem.(map[string]any)}
-					}
-				}
-				if len(items) > 0 {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = items
-				} else {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = []any{}
+			for _, key := range slices.Sorted(maps.Keys(recordMap)) {
+				if key == "id" {
+					continue
 				}
-			case "integer":
-				minimum := 0
-				maximum := 100
-				if responseBodyPropertiesSchema.Minimum != nil {
-					minimum = int(*responseBodyPropertiesSchema.Minimum)
+				target, ok := refs[collection][key]
+				if !ok {
+					target = foreignKeyCollection(key, collections)
 				}
-				if responseBodyPropertiesSchema.Maximum != nil {
-					maximum = int(*responseBodyPropertiesSchema.Maximum)
+				if target == "" || target == collection {
+					continue
 				}
-				if responseBodyPropertiesSchema.Default != nil {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = responseBodyPropertiesSchema.Default.Value
-				} else {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = minimum
+				if ids := recordIds(target); len(ids) > 0 {
+					recordMap[key] = foreignKeyValue(recordMap[key], ids[i%len(ids)])
 				}
-				if genExamples {
-					responseBody.(map[string]any)[responseBodyProperties.Key()] = gofakeit.IntRange(minimum, maximum)
+			}
+			for _, param := range slices.Sorted(maps.Keys(parents[collection])) {
+				parent := parents[collection][param]
+				if ids := recordIds(parent); parent != collection && len(ids) > 0 {
+					field := parentField(param, parent)
+					recordMap[field] = foreignKeyValue(recordMap[field], ids[i%len(ids)])
 				}
-			case "boolean":
-				responseBody.(map[string]any)[responseBodyProperties.Key()] = false
-			default:
-				responseBody.(map[string]any)[responseBodyProperties.Key()] = nil
 			}
-		} else {
-			responseBody = schemaToPropertyMapV2(responseBodyPropertiesSchema.ParentProxy, definitions, responseBody, maxRecursion, recursionDepth, genExamples)
 		}
 	}
+}
 
-	return responseBody
+func readSpecDocument(specFilename string) (libopenapi.Document, error) {
+	api, err := os.ReadFile(filepath.Clean(specFilename))
+	if err != nil {
+		return nil, err
+	}
+
+	return libopenapi.NewDocumentWithConfiguration(api, &datamodel.DocumentConfiguration{
+		BasePath:            filepath.Dir(specFilename),
+		SpecFilePath:        filepath.Base(specFilename),
+		AllowFileReferences: true,
+	})
 }
 
-func SpecV2toRequestStructureMap(specFilename string, maxRecursionDepth int, genExamples bool) (map[string]map[string][]RequestStructure, error) {
-	apiDir, err := os.OpenRoot(".")
+func detectSpecMajorVersion(document libopenapi.Document) (int, error) {
+	specInfo := document.GetSpecInfo()
+	switch {
+	case specInfo.SpecType == utils.OpenApi2 && strings.HasPrefix(specInfo.Version, "2."):
+		return 2, nil
+	case specInfo.SpecType == utils.OpenApi3 && strings.HasPrefix(specInfo.Version, "3."):
+		return 3, nil
+	}
+
+	return 0, fmt.Errorf("unsupported spec version '%s: %s', only 'swagger: 2.x' and 'openapi: 3.x' are supported", specInfo.SpecType, specInfo.Version)
+}
+
+func SpecToRequestStructureMap(specFilename string, opts GenerateOptions) (map[string]map[string][]RequestStructure, error) {
+	if _, _, err := parseItemRange(opts.ArrayItems); err != nil {
+		return map[string]map[string][]RequestStructure{}, err
+	}
+	if _, err := parseUnionBranch(opts.UnionBranch); err != nil {
+		return map[string]map[string][]RequestStructure{}, err
+	}
+	if err := opts.Chaos.check(); err != nil {
+		return map[string]map[string][]RequestStructure{}, err
+	}
+	nameRules, err := compileNameRules(opts.NameRules)
 	if err != nil {
 		return map[string]map[string][]RequestStructure{}, err
 	}
+	opts.nameRules = nameRules
 
-	api, err := apiDir.ReadFile(specFilename)
+	document, err := readSpecDocument(specFilename)
 	if err != nil {
 		return map[string]map[string][]RequestStructure{}, err
 	}
 
-	document, err := libopenapi.NewDocument(api)
+	specMajorVersion := opts.SpecMajorVersion
+	if specMajorVersion == 0 {
+		specMajorVersion, err = detectSpecMajorVersion(document)
+		if err != nil {
+			return map[string]map[string][]RequestStructure{}, err
+		}
+	}
+
+	switch specMajorVersion {
+	case 2:
+		return specV2toRequestStructureMap(document, opts)
+	case 3:
+		return specV3toRequestStructureMap(document, opts)
+	}
+
+	return map[string]map[string][]RequestStructure{}, fmt.Errorf("unsupported spec major version %d, only 2 and 3 are supported", specMajorVersion)
+}
+
+func SpecV2toRequestStructureMap(specFilename string, maxRecursionDepth int, genExamples bool) (map[string]map[string][]RequestStructure, error) {
+	document, err := readSpecDocument(specFilename)
 	if err != nil {
 		return map[string]map[string][]RequestStructure{}, err
 	}
 
-	docModel, errors := document.BuildV2Model()
+	return specV2toRequestStructureMap(document, GenerateOptions{
+		MaxRecursionDepth: maxRecursionDepth,
+		GenExamples:       genExamples,
+	})
+}
 
-	if errors != nil {
-		return map[string]map[string][]RequestStructure{}, err
+func specV2toRequestStructureMap(document libopenapi.Document, opts GenerateOptions) (map[string]map[string][]RequestStructure, error) {
+	docModel, err := document.BuildV2Model()
+	if err != nil {
+		return map[string]map[string][]RequestStructure{}, fmt.Errorf("cannot read the spec as a version 2 spec: %w", err)
 	}
 
+	var generateErr error
+	opts.generateErr = &generateErr
+	opts.faker = gofakeit.New(opts.Seed)
+
 	featureFileDataStructure := map[string]map[string][]RequestStructure{}
 	basePath := docModel.Model.BasePath
+	definitions := orderedmap.New[string, *base.SchemaProxy]()
+	if docModel.Model.Definitions != nil {
+		definitions = docModel.Model.Definitions.Definitions
+	}
 
 	for pathPairs := docModel.Model.Paths.PathItems.First(); pathPairs != nil; pathPairs = pathPairs.Next() {
 		pathName := pathPairs.Key()
@@ -452,22 +2408,23 @@ func SpecV2toRequestStructureMap(specFilename string, maxRecursionDepth int, gen
 			requestParams = append(requestParams, requestParam)
 			pathName = strings.ReplaceAll(pathName, param[1], requestParam)
 		}
-		dbEntry := strings.ReplaceAll(re.ReplaceAllString(pathPairs.Key(), ""), "/", "-")
-		dbEntry = strings.ReplaceAll(dbEntry, "--", "-")
-		dbEntry = strings.Split(dbEntry, "?")[0]
-		if len(dbEntry) > 0 && dbEntry[0] == '-' {
-			dbEntry = dbEntry[1:]
-		}
-		if len(dbEntry) > 0 && dbEntry[len(dbEntry)-1] == '-' {
-			dbEntry = dbEntry[:len(dbEntry)-1]
-		}
+		dbEntry := pathDbEntry(pathPairs.Key())
 		pathName = re.ReplaceAllString(pathName, ":$1")
+		var parents map[string]string
+		if opts.DbRecords > 0 || opts.Stateful {
+			parents = dbParents(pathPairs.Key(), itemParam(pathName, requestParams))
+		}
 		pathItem := pathPairs.Value()
 		pathOperations := pathItem.GetOperations()
 		for pathOperationPairs := pathOperations.First(); pathOperationPairs != nil; pathOperationPairs = pathOperationPairs.Next() {
 			httpMethod := strings.ToLower(pathOperationPairs.Key())
 			var responseBody any
+			var responseVariants []any
+			var dbRecords []any
+			var dbRefs map[string]string
+			var dbSchema string
 			var responseCode string
+			var successResponse *v2high.Response
 			for responseCodes := pathOperationPairs.Value().Responses.Codes.First(); responseCodes != nil; responseCodes = responseCodes.Next() {
 				responseCodesInt, err := strconv.Atoi(responseCodes.Key())
 				if err != nil {
@@ -475,13 +2432,39 @@ func SpecV2toRequestStructureMap(specFilename string, maxRecursionDepth int, gen
 				}
 				if responseCodesInt < 300 {
 					responseCode = responseCodes.Key()
+					successResponse = responseCodes.Value()
+					if httpMethod == "get" && opts.DbRecords > 0 && responseCodes.Value().Schema != nil {
+						dbRecords, dbRefs, dbSchema = dbRecordsV2(responseCodes.Value().Schema, definitions, itemParam(pathName, requestParams), opts)
+					}
+					if opts.GenExamples && responseCodes.Value().Examples != nil && responseCodes.Value().Examples.Values.Len() > 0 {
+						responseBody = nodeValue(responseCodes.Value().Examples.Values.First().Value())
+						continue
+					}
 					if responseCodes.Value().Schema != nil {
-						definitions := docModel.Model.Definitions.Definitions
-						responseBody = schemaToPropertyMapV2(responseCodes.Value().Schema, definitions, responseBody, maxRecursionDepth, 0, genExamples)
+						responseSchema := responseCodes.Value().Schema
+						responseBody, responseVariants = generateVariants(func(opts GenerateOptions) any {
+							return schemaToPropertyMapV2(responseSchema, definitions, responseBody, 0, opts)
+						}, opts)
 					}
 
 				}
 			}
+			var validation *RequestValidation
+			if opts.Validate {
+				validation = requestValidationV2(pathItem, pathOperationPairs.Value())
+			}
+			chaos := routeChaos(pathOperationPairs.Value().Extensions, opts)
+			responseHeaders := responseHeadersV2(successResponse, responseBody, pathName, opts)
+			produces := pathOperationPairs.Value().Produces
+			if len(produces) == 0 {
+				produces = docModel.Model.Produces
+			}
+			responseContent := responseContentV2(successResponse, produces, definitions, opts)
+			var responses map[string]any
+			var statusHeaders map[string]map[string]string
+			if opts.AllResponses {
+				responses, statusHeaders = responsesV2(pathOperationPairs.Value().Responses, pathName, definitions, opts)
+			}
 			if _, ok := featureFileDataStructure[httpMethod]; !ok {
 				featureFileDataStructure[httpMethod] = map[string][]RequestStructure{}
 			}
@@ -490,19 +2473,31 @@ func SpecV2toRequestStructureMap(specFilename string, maxRecursionDepth int, gen
 			}
 
 			req := RequestStructure{
-				Path:          pathName,
-				Method:        httpMethod,
-				DbEntry:       dbEntry,
-				ResponseCode:  responseCode,
-				ResponseBody:  responseBody,
-				RequestParams: requestParams,
+				Path:             pathName,
+				Method:           httpMethod,
+				DbEntry:          dbEntry,
+				ResponseCode:     responseCode,
+				ResponseBody:     responseBody,
+				ResponseVariants: responseVariants,
+				RequestParams:    requestParams,
+				DbRecords:        dbRecords,
+				DbRefs:           dbRefs,
+				DbSchema:         dbSchema,
+				DbParents:        parents,
+				Responses:        responses,
+				ResponseHeaders:  responseHeaders,
+				StatusHeaders:    statusHeaders,
+				ResponseContent:  responseContent,
+				Validation:       validation,
+				Chaos:            chaos,
+				OperationId:      pathOperationPairs.Value().OperationId,
 			}
 			if len(pathOperationPairs.Value().Parameters) > 0 {
 				var requestBody any
 				for _, parameter := range pathOperationPairs.Value().Parameters {
 					if parameter.In == "body" {
 						requestBody = map[string]any{}
-						requestBody = schemaToPropertyMapV2(parameter.Schema, docModel.Model.Definitions.Definitions, requestBody, maxRecursionDepth, 0, genExamples)
+						requestBody = schemaToPropertyMapV2(parameter.Schema, definitions, requestBody, 0, opts)
 						req.RequestBody = requestBody
 					}
 				}
@@ -513,20 +2508,29 @@ func SpecV2toRequestStructureMap(specFilename string, maxRecursionDepth int, gen
 				for _, parameter := range pathOperationPairs.Value().Parameters {
 					if parameter.In == "body" {
 						requestBody = map[string]any{}
-						requestBody = schemaToPropertyMapV2(parameter.Schema, docModel.Model.Definitions.Definitions, requestBody, maxRecursionDepth, 0, genExamples)
+						requestBody = schemaToPropertyMapV2(parameter.Schema, definitions, requestBody, 0, opts)
 						req.RequestBody = requestBody
 					}
 				}
 				for _, parameter := range pathOperationPairs.Value().Parameters {
 					if parameter.In == "query" {
 						req = RequestStructure{
-							Path:          fmt.Sprintf("%s?%s=", pathName, parameter.Name),
-							Method:        httpMethod,
-							DbEntry:       dbEntry,
-							ResponseCode:  responseCode,
-							ResponseBody:  responseBody,
-							RequestParams: requestParams,
-							RequestBody:   requestBody,
+							Path:             fmt.Sprintf("%s?%s=", pathName, parameter.Name),
+							Method:           httpMethod,
+							DbEntry:          dbEntry,
+							ResponseCode:     responseCode,
+							ResponseBody:     responseBody,
+							ResponseVariants: responseVariants,
+							RequestParams:    requestParams,
+							RequestBody:      requestBody,
+							DbParents:        parents,
+							Responses:        responses,
+							ResponseHeaders:  responseHeaders,
+							StatusHeaders:    statusHeaders,
+							ResponseContent:  responseContent,
+							Validation:       validation,
+							Chaos:            chaos,
+							OperationId:      pathOperationPairs.Value().OperationId,
 						}
 						featureFileDataStructure[httpMethod][pathName] = append(featureFileDataStructure[httpMethod][pathName], req)
 					}
@@ -535,32 +2539,58 @@ func SpecV2toRequestStructureMap(specFilename string, maxRecursionDepth int, gen
 		}
 	}
 
+	if generateErr != nil {
+		return map[string]map[string][]RequestStructure{}, generateErr
+	}
+
+	if opts.Stateful {
+		markCrudOperations(featureFileDataStructure)
+	}
+	if len(opts.Scenarios) > 0 {
+		if err := attachScenarios(featureFileDataStructure, opts.Scenarios); err != nil {
+			return map[string]map[string][]RequestStructure{}, err
+		}
+	}
+
 	return featureFileDataStructure, nil
 }
 
 func SpecV3toRequestStructureMap(specFilename string, maxRecursionDepth int, genExamples bool) (map[string]map[string][]RequestStructure, error) {
-	apiDir, err := os.OpenRoot(".")
+	document, err := readSpecDocument(specFilename)
 	if err != nil {
 		return map[string]map[string][]RequestStructure{}, err
 	}
 
-	api, err := apiDir.ReadFile(specFilename)
-	if err != nil {
-		return map[string]map[string][]RequestStructure{}, err
-	}
+	return specV3toRequestStructureMap(document, GenerateOptions{
+		MaxRecursionDepth: maxRecursionDepth,
+		GenExamples:       genExamples,
+	})
+}
 
-	document, err := libopenapi.NewDocument(api)
+func specV3toRequestStructureMap(document libopenapi.Document, opts GenerateOptions) (map[string]map[string][]RequestStructure, error) {
+	docModel, err := document.BuildV3Model()
 	if err != nil {
-		return map[string]map[string][]RequestStructure{}, err
+		return map[string]map[string][]RequestStructure{}, fmt.Errorf("cannot read the spec as a version 3 spec: %w", err)
 	}
 
-	docModel, errors := document.BuildV3Model()
+	var generateErr error
+	opts.generateErr = &generateErr
+	opts.faker = gofakeit.New(opts.Seed)
 
-	if errors != nil {
-		return map[string]map[string][]RequestStructure{}, err
+	featureFileDataStructure := map[string]map[string][]RequestStructure{}
+	definitions := &orderedmapv2.OrderedMap[string, *base.SchemaProxy]{}
+	if docModel.Model.Components != nil {
+		definitions = docModel.Model.Components.Schemas.OrderedMap
 	}
 
-	featureFileDataStructure := map[string]map[string][]RequestStructure{}
+	operationEntries := map[string]string{}
+	for pathPairs := docModel.Model.Paths.PathItems.First(); pathPairs != nil; pathPairs = pathPairs.Next() {
+		for _, operation := range pathPairs.Value().GetOperations().FromOldest() {
+			if operation.OperationId != "" {
+				operationEntries[operation.OperationId] = pathDbEntry(pathPairs.Key())
+			}
+		}
+	}
 
 	for pathPairs := docModel.Model.Paths.PathItems.First(); pathPairs != nil; pathPairs = pathPairs.Next() {
 		pathName := pathPairs.Key()
@@ -572,22 +2602,25 @@ func SpecV3toRequestStructureMap(specFilename string, maxRecursionDepth int, gen
 			requestParams = append(requestParams, requestParam)
 			pathName = strings.ReplaceAll(pathName, param[1], requestParam)
 		}
-		dbEntry := strings.ReplaceAll(re.ReplaceAllString(pathPairs.Key(), ""), "/", "-")
-		dbEntry = strings.ReplaceAll(dbEntry, "--", "-")
-		dbEntry = strings.Split(dbEntry, "?")[0]
-		if len(dbEntry) > 0 && dbEntry[0] == '-' {
-			dbEntry = dbEntry[1:]
-		}
-		if len(dbEntry) > 0 && dbEntry[len(dbEntry)-1] == '-' {
-			dbEntry = dbEntry[:len(dbEntry)-1]
-		}
+		dbEntry := pathDbEntry(pathPairs.Key())
 		pathName = re.ReplaceAllString(pathName, ":$1")
+		var parents map[string]string
+		if opts.DbRecords > 0 || opts.Stateful {
+			parents = dbParents(pathPairs.Key(), itemParam(pathName, requestParams))
+		}
 		pathItem := pathPairs.Value()
 		pathOperations := pathItem.GetOperations()
 		for pathOperationPairs := pathOperations.First(); pathOperationPairs != nil; pathOperationPairs = pathOperationPairs.Next() {
 			httpMethod := strings.ToLower(pathOperationPairs.Key())
 			var responseBody any
+			var responseVariants []any
+			var responseExamples map[string]any
+			var dbRecords []any
+			var dbRefs map[string]string
+			var dbSchema string
+			var linkRefs map[string]string
 			var responseCode string
+			var successResponse *v3high.Response
 			for responseCodes := pathOperationPairs.Value().Responses.Codes.First(); responseCodes != nil; responseCodes = responseCodes.Next() {
 				responseCodesInt, err := strconv.Atoi(responseCodes.Key())
 				if err != nil {
@@ -595,19 +2628,59 @@ func SpecV3toRequestStructureMap(specFilename string, maxRecursionDepth int, gen
 				}
 				if responseCodesInt < 300 {
 					responseCode = responseCodes.Key()
+					successResponse = responseCodes.Value()
+					if opts.DbRecords > 0 && orderedmap.Len(responseCodes.Value().Links) > 0 {
+						linkRefs = linkRefsV3(responseCodes.Value().Links, operationEntries)
+					}
 					if responseCodes.Value().Content == nil {
 						continue
 					}
-					if responseCodes.Value().Content.Newest().Value.Schema != nil {
-						definitions := &orderedmapv2.OrderedMap[string, *base.SchemaProxy]{}
-						if docModel.Model.Components != nil {
-							definitions = docModel.Model.Components.Schemas.OrderedMap
+					mediaType := jsonMediaTypeV3(responseCodes.Value().Content)
+					if mediaType == nil {
+						continue
+					}
+					if httpMethod == "get" && opts.DbRecords > 0 && mediaType.Schema != nil {
+						dbRecords, dbRefs, dbSchema = dbRecordsV3(mediaType.Schema, definitions, itemParam(pathName, requestParams), opts)
+					}
+					if opts.GenExamples {
+						exampleBody, examples, ok := mediaTypeExampleV3(mediaType, opts.ExampleName)
+						if ok {
+							responseBody = exampleBody
+							responseExamples = examples
+							continue
 						}
-						responseBody = schemaToPropertyMapV3(responseCodes.Value().Content.Newest().Value.Schema, definitions, responseBody, maxRecursionDepth, 0, genExamples)
+					}
+					if mediaType.Schema != nil {
+						responseSchema := mediaType.Schema
+						responseBody, responseVariants = generateVariants(func(opts GenerateOptions) any {
+							return schemaToPropertyMapV3(responseSchema, definitions, responseBody, 0, opts)
+						}, opts)
 					}
 
 				}
 			}
+			if len(linkRefs) > 0 {
+				if dbRefs == nil {
+					dbRefs = map[string]string{}
+				}
+				for property, collection := range linkRefs {
+					if _, ok := dbRefs[property]; !ok {
+						dbRefs[property] = collection
+					}
+				}
+			}
+			var validation *RequestValidation
+			if opts.Validate {
+				validation = requestValidationV3(pathItem, pathOperationPairs.Value())
+			}
+			chaos := routeChaos(pathOperationPairs.Value().Extensions, opts)
+			responseHeaders := responseHeadersV3(successResponse, responseBody, pathName, definitions, opts)
+			responseContent := responseContentV3(successResponse, definitions, opts)
+			var responses map[string]any
+			var statusHeaders map[string]map[string]string
+			if opts.AllResponses {
+				responses, statusHeaders = responsesV3(pathOperationPairs.Value().Responses, pathName, definitions, opts)
+			}
 			if _, ok := featureFileDataStructure[httpMethod]; !ok {
 				featureFileDataStructure[httpMethod] = map[string][]RequestStructure{}
 			}
@@ -616,12 +2689,25 @@ func SpecV3toRequestStructureMap(specFilename string, maxRecursionDepth int, gen
 			}
 
 			req := RequestStructure{
-				Path:          pathName,
-				Method:        httpMethod,
-				DbEntry:       dbEntry,
-				ResponseCode:  responseCode,
-				ResponseBody:  responseBody,
-				RequestParams: requestParams,
+				Path:             pathName,
+				Method:           httpMethod,
+				DbEntry:          dbEntry,
+				ResponseCode:     responseCode,
+				ResponseBody:     responseBody,
+				ResponseVariants: responseVariants,
+				ResponseExamples: responseExamples,
+				RequestParams:    requestParams,
+				DbRecords:        dbRecords,
+				DbRefs:           dbRefs,
+				DbSchema:         dbSchema,
+				DbParents:        parents,
+				Responses:        responses,
+				ResponseHeaders:  responseHeaders,
+				StatusHeaders:    statusHeaders,
+				ResponseContent:  responseContent,
+				Validation:       validation,
+				Chaos:            chaos,
+				OperationId:      pathOperationPairs.Value().OperationId,
 			}
 
 			var requestBody any
@@ -629,11 +2715,7 @@ func SpecV3toRequestStructureMap(specFilename string, maxRecursionDepth int, gen
 				requestBodyContent := pathOperationPairs.Value().RequestBody.Content
 				for requestBodyPairs := requestBodyContent.First(); requestBodyPairs != nil; requestBodyPairs = requestBodyPairs.Next() {
 					requestBodySchema := requestBodyPairs.Value().Schema
-					definitions := &orderedmapv2.OrderedMap[string, *base.SchemaProxy]{}
-					if docModel.Model.Components != nil {
-						definitions = docModel.Model.Components.Schemas.OrderedMap
-					}
-					requestBody = schemaToPropertyMapV3(requestBodySchema, definitions, requestBody, maxRecursionDepth, 0, genExamples)
+					requestBody = schemaToPropertyMapV3(requestBodySchema, definitions, requestBody, 0, opts)
 					req.RequestBody = requestBody
 				}
 			}
@@ -642,13 +2724,23 @@ func SpecV3toRequestStructureMap(specFilename string, maxRecursionDepth int, gen
 				for _, parameter := range pathOperationPairs.Value().Parameters {
 					if parameter.In == "query" {
 						req = RequestStructure{
-							Path:          fmt.Sprintf("%s?%s=", pathName, parameter.Name),
-							Method:        httpMethod,
-							DbEntry:       dbEntry,
-							ResponseCode:  responseCode,
-							ResponseBody:  responseBody,
-							RequestParams: requestParams,
-							RequestBody:   requestBody,
+							Path:             fmt.Sprintf("%s?%s=", pathName, parameter.Name),
+							Method:           httpMethod,
+							DbEntry:          dbEntry,
+							ResponseCode:     responseCode,
+							ResponseBody:     responseBody,
+							ResponseVariants: responseVariants,
+							ResponseExamples: responseExamples,
+							RequestParams:    requestParams,
+							RequestBody:      requestBody,
+							DbParents:        parents,
+							Responses:        responses,
+							ResponseHeaders:  responseHeaders,
+							StatusHeaders:    statusHeaders,
+							ResponseContent:  responseContent,
+							Validation:       validation,
+							Chaos:            chaos,
+							OperationId:      pathOperationPairs.Value().OperationId,
 						}
 						featureFileDataStructure[httpMethod][pathName] = append(featureFileDataStructure[httpMethod][pathName], req)
 					}
@@ -657,32 +2749,95 @@ func SpecV3toRequestStructureMap(specFilename string, maxRecursionDepth int, gen
 		}
 	}
 
+	if generateErr != nil {
+		return map[string]map[string][]RequestStructure{}, generateErr
+	}
+
+	if opts.Stateful {
+		markCrudOperations(featureFileDataStructure)
+	}
+	if len(opts.Scenarios) > 0 {
+		if err := attachScenarios(featureFileDataStructure, opts.Scenarios); err != nil {
+			return map[string]map[string][]RequestStructure{}, err
+		}
+	}
+
 	return featureFileDataStructure, nil
 }
 
-func GenerateDbFile(featureFileDataStructure map[string]map[string][]RequestStructure) (string, error) {
+func dbEntryCollections(featureFileDataStructure map[string]map[string][]RequestStructure) map[string][]any {
 	dbEntryMap := map[string][]any{}
-	dbCallMap := map[string]map[string]bool{}
-	for _, calls := range featureFileDataStructure {
-		for _, filterPaths := range calls {
-			for _, filterPath := range filterPaths {
+	itemRecords := map[string]bool{}
+	refs := map[string]map[string]string{}
+	schemaRefs := map[string]map[string]string{}
+	schemaCollections := map[string][]string{}
+	parents := map[string]map[string]string{}
+	for _, method := range slices.Sorted(maps.Keys(featureFileDataStructure)) {
+		calls := featureFileDataStructure[method]
+		for _, callPath := range sortedRoutePaths(calls) {
+			for _, filterPath := range calls[callPath] {
 				if _, ok := dbEntryMap[filterPath.DbEntry]; !ok {
 					dbEntryMap[filterPath.DbEntry] = []any{}
+					refs[filterPath.DbEntry] = map[string]string{}
+					parents[filterPath.DbEntry] = map[string]string{}
 				}
-				path := fmt.Sprintf("/%s", filterPath.DbEntry)
-				if len(filterPath.RequestParams) > 0 {
-					path = fmt.Sprintf("%s/:%s", path, strings.Join(filterPath.RequestParams, "/:"))
-				}
-				if _, ok := dbCallMap[path]; !ok {
-					dbCallMap[path] = map[string]bool{
-						filterPath.Method: true,
+				maps.Copy(refs[filterPath.DbEntry], filterPath.DbRefs)
+				maps.Copy(parents[filterPath.DbEntry], filterPath.DbParents)
+				if filterPath.DbSchema != "" {
+					if schemaRefs[filterPath.DbSchema] == nil {
+						schemaRefs[filterPath.DbSchema] = map[string]string{}
 					}
-				} else if !dbCallMap[path][filterPath.Method] {
-					dbCallMap[path][filterPath.Method] = true
+					maps.Copy(schemaRefs[filterPath.DbSchema], filterPath.DbRefs)
+					if !slices.Contains(schemaCollections[filterPath.DbSchema], filterPath.DbEntry) {
+						schemaCollections[filterPath.DbSchema] = append(schemaCollections[filterPath.DbSchema], filterPath.DbEntry)
+					}
+				}
+				if len(filterPath.DbRecords) == 0 || itemRecords[filterPath.DbEntry] {
+					continue
+				}
+				isItem := itemParam(filterPath.Path, filterPath.RequestParams) != ""
+				if len(dbEntryMap[filterPath.DbEntry]) == 0 || isItem {
+					dbEntryMap[filterPath.DbEntry] = cloneValue(filterPath.DbRecords).([]any)
+					itemRecords[filterPath.DbEntry] = isItem
+				}
+			}
+		}
+	}
+	for schema, collections := range schemaCollections {
+		for _, collection := range collections {
+			for property, target := range schemaRefs[schema] {
+				if _, ok := refs[collection][property]; !ok {
+					refs[collection][property] = target
 				}
 			}
 		}
 	}
+	linkDbRecords(dbEntryMap, refs, parents)
+
+	return dbEntryMap
+}
+
+func cloneValue(value any) any {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		clone := map[string]any{}
+		for k, v := range typedValue {
+			clone[k] = cloneValue(v)
+		}
+		return clone
+	case []any:
+		clone := []any{}
+		for _, v := range typedValue {
+			clone = append(clone, cloneValue(v))
+		}
+		return clone
+	}
+
+	return value
+}
+
+func GenerateDbFile(featureFileDataStructure map[string]map[string][]RequestStructure) (string, error) {
+	dbEntryMap := dbEntryCollections(featureFileDataStructure)
 
 	dbJson, err := json.MarshalIndent(dbEntryMap, "", "  ")
 	if err != nil {
@@ -692,6 +2847,12 @@ func GenerateDbFile(featureFileDataStructure map[string]map[string][]RequestStru
 	return string(dbJson), nil
 }
 
+func sortedRoutePaths(calls map[string][]RequestStructure) []string {
+	return slices.SortedFunc(maps.Keys(calls), func(a string, b string) int {
+		return strings.Compare(strings.ReplaceAll(a, ":", "~"), strings.ReplaceAll(b, ":", "~"))
+	})
+}
+
 func GenerateServerFile(scheme string, port int, dbFilename string, featureFileDataStructure map[string]map[string][]RequestStructure) (string, error) {
 	featureFileContent := fmt.Sprintf(initServerTemplateHttp, dbFilename, dbFilename, port)
 	if scheme == "https" {
@@ -701,9 +2862,15 @@ func GenerateServerFile(scheme string, port int, dbFilename string, featureFileD
 	var rewriterData []string
 	dbEntryCalls := []RequestStructure{}
 	dbCallMap := map[string]map[string]bool{}
-	for _, calls := range featureFileDataStructure {
-		for _, filterPaths := range calls {
-			for _, filterPath := range filterPaths {
+	seeded := map[string]bool{}
+	routeKeys := map[string]string{}
+	for _, method := range slices.Sorted(maps.Keys(featureFileDataStructure)) {
+		calls := featureFileDataStructure[method]
+		for _, callPath := range sortedRoutePaths(calls) {
+			for _, filterPath := range calls[callPath] {
+				if len(filterPath.DbRecords) > 0 {
+					seeded[filterPath.DbEntry] = true
+				}
 				path := fmt.Sprintf("/%s", filterPath.DbEntry)
 				if len(filterPath.RequestParams) > 0 {
 					path = fmt.Sprintf("%s/:%s", path, strings.Join(filterPath.RequestParams, "/:"))
@@ -720,15 +2887,26 @@ func GenerateServerFile(scheme string, port int, dbFilename string, featureFileD
 					dbCallMap[path][filterPath.Method] = true
 				}
 				if addCall {
+					routeKeys[fmt.Sprintf("%s %s", filterPath.Method, path)] = fmt.Sprintf("%s %s", strings.ToUpper(filterPath.Method), strings.Split(filterPath.Path, "?")[0])
 					dbEntryCalls = append(dbEntryCalls, RequestStructure{
-						Path:          path,
-						Method:        filterPath.Method,
-						Body:          filterPath.Body,
-						DbEntry:       filterPath.DbEntry,
-						ResponseBody:  filterPath.ResponseBody,
-						ResponseCode:  filterPath.ResponseCode,
-						RequestParams: filterPath.RequestParams,
-						RequestBody:   filterPath.RequestBody,
+						Path:             path,
+						Method:           filterPath.Method,
+						Body:             filterPath.Body,
+						DbEntry:          filterPath.DbEntry,
+						ResponseBody:     filterPath.ResponseBody,
+						ResponseVariants: filterPath.ResponseVariants,
+						ResponseExamples: filterPath.ResponseExamples,
+						ResponseCode:     filterPath.ResponseCode,
+						RequestParams:    filterPath.RequestParams,
+						RequestBody:      filterPath.RequestBody,
+						DbParents:        filterPath.DbParents,
+						Responses:        filterPath.Responses,
+						ResponseHeaders:  filterPath.ResponseHeaders,
+						StatusHeaders:    filterPath.StatusHeaders,
+						ResponseContent:  filterPath.ResponseContent,
+						Validation:       filterPath.Validation,
+						Chaos:            filterPath.Chaos,
+						Crud:             filterPath.Crud,
 					})
 				}
 			}
@@ -736,7 +2914,15 @@ func GenerateServerFile(scheme string, port int, dbFilename string, featureFileD
 	}
 
 	rewriterData[len(rewriterData)-1] = strings.Replace(rewriterData[len(rewriterData)-1], ",", "", 1)
-	featureFileContent = fmt.Sprintf("%s%s", featureFileContent, fmt.Sprintf(rewriterDataTemplate, dbFilename, strings.Join(rewriterData, "\n")))
+	adminRoutesJson, err := json.Marshal(adminRoutes(featureFileDataStructure))
+	if err != nil {
+		return "", err
+	}
+	scenariosJson, err := json.Marshal(operationScenarios(featureFileDataStructure))
+	if err != nil {
+		return "", err
+	}
+	featureFileContent = fmt.Sprintf("%s%s", featureFileContent, fmt.Sprintf(rewriterDataTemplate, dbFilename, adminRoutesJson, scenariosJson, strings.Join(rewriterData, "\n")))
 
 	for _, call := range dbEntryCalls {
 		pathline := fmt.Sprintf("/%s", call.DbEntry)
@@ -755,11 +2941,140 @@ func GenerateServerFile(scheme string, port int, dbFilename string, featureFileD
 			}
 			response = strings.Replace(string(responseJson), "}", "\t}", 1)
 		}
+		if len(call.ResponseVariants) > 0 {
+			variantsJson, err := json.MarshalIndent(call.ResponseVariants, "", "\t\t")
+			if err != nil {
+				return "", err
+			}
+			response = fmt.Sprintf(variantTemplate, strings.ToUpper(call.Method), call.Path, variantsJson)
+		}
+		if len(call.ResponseExamples) > 0 {
+			examplesJson, err := json.MarshalIndent(call.ResponseExamples, "", "\t\t")
+			if err != nil {
+				return "", err
+			}
+			response = fmt.Sprintf(preferredExampleTemplate, examplesJson, response)
+		}
+		validateRequest := ""
+		if call.Validation != nil {
+			validationJson, err := json.Marshal(call.Validation)
+			if err != nil {
+				return "", err
+			}
+			validateRequest = fmt.Sprintf(validateRequestTemplate, validationJson)
+		}
+		chaos := ""
+		if call.Chaos != nil {
+			chaosJson, err := json.Marshal(call.Chaos)
+			if err != nil {
+				return "", err
+			}
+			chaos = fmt.Sprintf(routeChaosTemplate, chaosJson)
+		}
+		responseLines := []string{}
+		if len(call.ResponseHeaders) > 0 || len(call.Responses) > 0 {
+			responseHeaders := call.ResponseHeaders
+			if responseHeaders == nil {
+				responseHeaders = map[string]string{}
+			}
+			responseHeadersJson, err := json.Marshal(responseHeaders)
+			if err != nil {
+				return "", err
+			}
+			responseLines = append(responseLines, fmt.Sprintf(responseHeadersTemplate, responseHeadersJson))
+		}
+		if len(call.Responses) > 0 {
+			responsesJson, err := json.Marshal(call.Responses)
+			if err != nil {
+				return "", err
+			}
+			statusHeaders := call.StatusHeaders
+			if statusHeaders == nil {
+				statusHeaders = map[string]map[string]string{}
+			}
+			statusHeadersJson, err := json.Marshal(statusHeaders)
+			if err != nil {
+				return "", err
+			}
+			responseLines = append(responseLines, fmt.Sprintf(selectResponseTemplate, routeKeys[fmt.Sprintf("%s %s", call.Method, call.Path)], responsesJson, statusHeadersJson))
+		}
+		if len(responseLines) > 0 {
+			responseLines = append(responseLines, setResponseHeaders)
+		}
+		if call.Crud != nil {
+			_, parents := collectionParams(call, call.RequestParams)
+			if parents == nil {
+				parents = map[string]string{}
+			}
+			parentsJson, err := json.Marshal(parents)
+			if err != nil {
+				return "", err
+			}
+			responseHeaders := call.ResponseHeaders
+			if responseHeaders == nil {
+				responseHeaders = map[string]string{}
+			}
+			responseHeadersJson, err := json.Marshal(responseHeaders)
+			if err != nil {
+				return "", err
+			}
+			selectResponse := ""
+			if len(call.Responses) > 0 {
+				selectResponse = "\n\t" + responseLines[1]
+			}
+			template := []byte("undefined")
+			if call.ResponseBody != nil {
+				template, err = json.Marshal(call.ResponseBody)
+				if err != nil {
+					return "", err
+				}
+			}
+			statusCode := call.ResponseCode
+			if statusCode == "" {
+				statusCode = strconv.Itoa(http.StatusOK)
+				if call.Crud.Action == "create" {
+					statusCode = strconv.Itoa(http.StatusCreated)
+				}
+			}
+			specPath := strings.SplitN(routeKeys[fmt.Sprintf("%s %s", call.Method, call.Path)], " ", 2)[1]
+			serverCall := fmt.Sprintf(crudServerCallTemplate, call.Method, call.Path, chaos, logline, validateRequest, responseHeadersJson, selectResponse, call.Crud.Action, call.DbEntry, parentsJson, call.Crud.IdParam, statusCode, template, specPath)
+			featureFileContent = fmt.Sprintf("%s%s", featureFileContent, serverCall)
+			continue
+		}
+		if strings.ToLower(call.Method) == "get" && seeded[call.DbEntry] {
+			id, parents := collectionParams(call, call.RequestParams)
+			if parents == nil {
+				parents = map[string]string{}
+			}
+			parentsJson, err := json.Marshal(parents)
+			if err != nil {
+				return "", err
+			}
+			selectResponse := ""
+			if len(responseLines) > 0 {
+				specPath := strings.SplitN(routeKeys[fmt.Sprintf("%s %s", call.Method, call.Path)], " ", 2)[1]
+				responseLines[len(responseLines)-1] = fmt.Sprintf(seededResponseHeaders, specPath)
+				selectResponse = "\n\t" + strings.Join(responseLines, "\n\t")
+			}
+			serverCall := fmt.Sprintf(seededServerCallTemplate, call.Method, call.Path, chaos, logline, validateRequest, call.DbEntry, parentsJson, id, call.ResponseCode, selectResponse)
+			featureFileContent = fmt.Sprintf("%s%s", featureFileContent, serverCall)
+			continue
+		}
 		addWriteToDbFunc := ""
 		if strings.ToLower(call.Method) != "get" {
 			addWriteToDbFunc = "checkWriteToDb();"
 		}
-		serverCall := fmt.Sprintf(serverCallTemplate, call.Method, call.Path, logline, call.ResponseCode, response, addWriteToDbFunc)
+		if len(responseLines) > 0 {
+			addWriteToDbFunc = strings.TrimSuffix(fmt.Sprintf("%s\n\t%s", strings.Join(responseLines, "\n\t"), addWriteToDbFunc), "\n\t")
+		}
+		if len(call.ResponseContent) > 0 {
+			contentJson, err := json.Marshal(call.ResponseContent)
+			if err != nil {
+				return "", err
+			}
+			addWriteToDbFunc = strings.TrimPrefix(fmt.Sprintf("%s\n\t%s", addWriteToDbFunc, fmt.Sprintf(sendContentTemplate, call.ResponseCode, contentJson)), "\n\t")
+		}
+		serverCall := fmt.Sprintf(serverCallTemplate, call.Method, call.Path, chaos, logline, validateRequest, call.ResponseCode, response, addWriteToDbFunc)
 		featureFileContent = fmt.Sprintf("%s%s", featureFileContent, serverCall)
 	}
 	endServerFile := endServerTemplateHttp
@@ -1,15 +1,24 @@
 package genmock
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"math"
 	"net"
+	"net/mail"
+	"net/url"
 	"os"
+	"path/filepath"
 	"slices"
+	"strconv"
 	"testing"
 	"time"
 
 	"go.yaml.in/yaml/v4"
 
+	fakedata "github.com/brianvoe/gofakeit/v7/data"
 	"github.com/google/uuid"
 	"github.com/pb33f/libopenapi/datamodel/high/base"
 	"github.com/stretchr/testify/assert"
@@ -33,6 +42,9 @@ func Test_RandStringBytesRmndr_ReturnsRandomString(t *testing.T) {
 func Test_generateExampleData_ReturnsCorrectStringFormat(t *testing.T) {
 	t.Parallel()
 
+	int64Pointer := func(i int64) *int64 {
+		return &i
+	}
 	tests := map[string]struct {
 		propSchema    *base.Schema
 		formatChecker func(string) bool
@@ -57,7 +69,7 @@ func Test_generateExampleData_ReturnsCorrectStringFormat(t *testing.T) {
 				Format: "date-time",
 			},
 			formatChecker: func(s string) bool {
-				_, err := time.Parse("2006-01-02 15:04:05.000000000 +0000 UTC", s)
+				_, err := time.Parse(time.RFC3339, s)
 
 				return assert.NoError(t, err)
 			},
@@ -112,6 +124,157 @@ func Test_generateExampleData_ReturnsCorrectStringFormat(t *testing.T) {
 				return assert.NotNil(t, ip)
 			},
 		},
+		"date": {
+			propSchema: &base.Schema{
+				Format: "date",
+			},
+			formatChecker: func(s string) bool {
+				_, err := time.Parse(time.DateOnly, s)
+
+				return assert.NoError(t, err)
+			},
+		},
+		"time": {
+			propSchema: &base.Schema{
+				Format: "time",
+			},
+			formatChecker: func(s string) bool {
+				_, err := time.Parse(time.TimeOnly, s)
+
+				return assert.NoError(t, err)
+			},
+		},
+		"duration": {
+			propSchema: &base.Schema{
+				Format: "duration",
+			},
+			formatChecker: func(s string) bool {
+				return assert.Regexp(t, `^P\d+DT\d+H\d+M\d+S$`, s)
+			},
+		},
+		"email": {
+			propSchema: &base.Schema{
+				Format: "email",
+			},
+			formatChecker: func(s string) bool {
+				_, err := mail.ParseAddress(s)
+
+				return assert.NoError(t, err)
+			},
+		},
+		"uri": {
+			propSchema: &base.Schema{
+				Format: "uri",
+			},
+			formatChecker: func(s string) bool {
+				parsed, err := url.Parse(s)
+
+				return assert.NoError(t, err) && assert.NotEmpty(t, parsed.Scheme)
+			},
+		},
+		"hostname": {
+			propSchema: &base.Schema{
+				Format: "hostname",
+			},
+			formatChecker: func(s string) bool {
+				return assert.Regexp(t, `^[a-z0-9-]+(\.[a-z0-9-]+)+$`, s)
+			},
+		},
+		"ipv4": {
+			propSchema: &base.Schema{
+				Format: "ipv4",
+			},
+			formatChecker: func(s string) bool {
+				ip := net.ParseIP(s)
+
+				return assert.NotNil(t, ip) && assert.NotNil(t, ip.To4())
+			},
+		},
+		"ipv6": {
+			propSchema: &base.Schema{
+				Format: "ipv6",
+			},
+			formatChecker: func(s string) bool {
+				ip := net.ParseIP(s)
+
+				return assert.NotNil(t, ip) && assert.Contains(t, s, ":")
+			},
+		},
+		"byte": {
+			propSchema: &base.Schema{
+				Format: "byte",
+			},
+			formatChecker: func(s string) bool {
+				_, err := base64.StdEncoding.DecodeString(s)
+
+				return assert.NoError(t, err)
+			},
+		},
+		"password": {
+			propSchema: &base.Schema{
+				Format: "password",
+			},
+			formatChecker: func(s string) bool {
+				return assert.GreaterOrEqual(t, len(s), 8)
+			},
+		},
+		"pattern": {
+			propSchema: &base.Schema{
+				Format:  "email",
+				Pattern: `^[A-Z]{2}\d{4}$`,
+			},
+			formatChecker: func(s string) bool {
+				return assert.Regexp(t, `^[A-Z]{2}\d{4}$`, s)
+			},
+		},
+		"length bounds": {
+			propSchema: &base.Schema{
+				MinLength: int64Pointer(20),
+				MaxLength: int64Pointer(22),
+			},
+			formatChecker: func(s string) bool {
+				return assert.GreaterOrEqual(t, len(s), 20) && assert.LessOrEqual(t, len(s), 22)
+			},
+		},
+		"pattern within length bounds": {
+			propSchema: &base.Schema{
+				Pattern:   `^[A-Z]+-\d$`,
+				MaxLength: int64Pointer(4),
+			},
+			formatChecker: func(s string) bool {
+				return assert.Regexp(t, `^[A-Z]+-\d$`, s) && assert.LessOrEqual(t, len(s), 4)
+			},
+		},
+		"byte within length bounds": {
+			propSchema: &base.Schema{
+				Format:    "byte",
+				MinLength: int64Pointer(6),
+				MaxLength: int64Pointer(10),
+			},
+			formatChecker: func(s string) bool {
+				_, err := base64.StdEncoding.DecodeString(s)
+
+				return assert.NoError(t, err) && assert.Len(t, s, 8)
+			},
+		},
+		"format within length bounds": {
+			propSchema: &base.Schema{
+				Format:    "password",
+				MaxLength: int64Pointer(6),
+			},
+			formatChecker: func(s string) bool {
+				return assert.Len(t, s, 6)
+			},
+		},
+		"format longer than length bounds": {
+			propSchema: &base.Schema{
+				Format:    "uuid",
+				MaxLength: int64Pointer(8),
+			},
+			formatChecker: func(s string) bool {
+				return assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`, s)
+			},
+		},
 	}
 
 	for name, data := range tests {
@@ -119,7 +282,7 @@ func Test_generateExampleData_ReturnsCorrectStringFormat(t *testing.T) {
 			t.Parallel()
 
 			// Act
-			result := generateExampleData(data.propSchema)
+			result := generateExampleData(data.propSchema, GenerateOptions{})
 
 			// Assert
 			assert.NotEmpty(t, result)
@@ -128,6 +291,353 @@ func Test_generateExampleData_ReturnsCorrectStringFormat(t *testing.T) {
 	}
 }
 
+func Test_generateExampleData_UsesPropertyNameRules(t *testing.T) {
+	t.Parallel()
+
+	customRules, err := compileNameRules([]NameRule{
+		{Pattern: `(?i)^sku$`, Function: "uuid"},
+		{Pattern: `^contactEmail$`, Function: "city"},
+	})
+	require.NoError(t, err)
+	tests := map[string]struct {
+		propertyName string
+		propSchema   *base.Schema
+		checker      func(string) bool
+	}{
+		"first name": {
+			propertyName: "firstName",
+			propSchema:   &base.Schema{},
+			checker: func(s string) bool {
+				return slices.Contains(fakedata.Person["first"], s)
+			},
+		},
+		"email": {
+			propertyName: "work_email",
+			propSchema:   &base.Schema{},
+			checker: func(s string) bool {
+				_, err := mail.ParseAddress(s)
+
+				return err == nil
+			},
+		},
+		"city": {
+			propertyName: "city",
+			propSchema:   &base.Schema{},
+			checker: func(s string) bool {
+				return slices.Contains(fakedata.Address["city"], s)
+			},
+		},
+		"custom rule": {
+			propertyName: "SKU",
+			propSchema:   &base.Schema{},
+			checker: func(s string) bool {
+				_, err := uuid.Parse(s)
+
+				return err == nil
+			},
+		},
+		"custom rule before default rules": {
+			propertyName: "contactEmail",
+			propSchema:   &base.Schema{},
+			checker: func(s string) bool {
+				return slices.Contains(fakedata.Address["city"], s)
+			},
+		},
+		"format before name": {
+			propertyName: "email",
+			propSchema:   &base.Schema{Format: "uuid"},
+			checker: func(s string) bool {
+				_, err := uuid.Parse(s)
+
+				return err == nil
+			},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result := generateExampleData(data.propSchema, GenerateOptions{propertyName: data.propertyName, nameRules: customRules})
+
+			// Assert
+			assert.True(t, data.checker(result), "unexpected value %s", result)
+		})
+	}
+}
+
+func Test_ParseNameRule_ReturnsRule(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value         string
+		expectedRule  NameRule
+		expectedError string
+	}{
+		"rule": {
+			value:        "(?i)^sku$=uuid",
+			expectedRule: NameRule{Pattern: "(?i)^sku$", Function: "uuid"},
+		},
+		"equals sign in pattern": {
+			value:        "^a=b$=word",
+			expectedRule: NameRule{Pattern: "^a=b$", Function: "word"},
+		},
+		"missing function": {
+			value:         "^sku$=",
+			expectedError: "invalid name rule '^sku$=', use '<regex>=<gofakeit function>'",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			result, err := ParseNameRule(data.value)
+
+			// Assert
+			if data.expectedError != "" {
+				require.EqualError(t, err, data.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, data.expectedRule, result)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_ReturnsNameRuleError(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, err := SpecToRequestStructureMap("./testdata/examplev3.yaml", GenerateOptions{NameRules: []NameRule{{Pattern: "^sku$", Function: "notAFunction"}}})
+
+	// Assert
+	require.EqualError(t, err, "unknown gofakeit function 'notAFunction' in name rule '^sku$'")
+}
+
+func Test_numberValue_RespectsConstraints(t *testing.T) {
+	t.Parallel()
+
+	float64Pointer := func(f float64) *float64 {
+		return &f
+	}
+	tests := map[string]struct {
+		schema     *base.Schema
+		schemaType string
+		checker    func(any) bool
+	}{
+		"integer within bounds": {
+			schema:     &base.Schema{Minimum: float64Pointer(5), Maximum: float64Pointer(7)},
+			schemaType: "integer",
+			checker: func(value any) bool {
+				integer, ok := value.(int)
+				return ok && integer >= 5 && integer <= 7
+			},
+		},
+		"integer exclusive bounds": {
+			schema: &base.Schema{
+				Minimum:          float64Pointer(1),
+				Maximum:          float64Pointer(3),
+				ExclusiveMinimum: &base.DynamicValue[bool, float64]{N: 0, A: true},
+				ExclusiveMaximum: &base.DynamicValue[bool, float64]{N: 0, A: true},
+			},
+			schemaType: "integer",
+			checker: func(value any) bool {
+				return value == 2
+			},
+		},
+		"integer multiple of": {
+			schema:     &base.Schema{Minimum: float64Pointer(1), Maximum: float64Pointer(50), MultipleOf: float64Pointer(5)},
+			schemaType: "integer",
+			checker: func(value any) bool {
+				integer, ok := value.(int)
+				return ok && integer%5 == 0 && integer >= 5 && integer <= 50
+			},
+		},
+		"int32 format": {
+			schema:     &base.Schema{Format: "int32", Minimum: float64Pointer(math.MaxInt32 - 1), Maximum: float64Pointer(math.MaxInt64)},
+			schemaType: "integer",
+			checker: func(value any) bool {
+				integer, ok := value.(int)
+				return ok && integer >= math.MaxInt32-1 && integer <= math.MaxInt32
+			},
+		},
+		"number 3.1 exclusive minimum": {
+			schema:     &base.Schema{ExclusiveMinimum: &base.DynamicValue[bool, float64]{N: 1, B: 10}, Maximum: float64Pointer(11)},
+			schemaType: "number",
+			checker: func(value any) bool {
+				number, ok := value.(float64)
+				return ok && number > 10 && number <= 11
+			},
+		},
+		"int64 full range": {
+			schema:     &base.Schema{Format: "int64", Minimum: float64Pointer(math.MinInt64), Maximum: float64Pointer(math.MaxInt64)},
+			schemaType: "integer",
+			checker: func(value any) bool {
+				integer, ok := value.(int)
+				return ok && integer > math.MinInt64+1024
+			},
+		},
+		"int64 near maximum": {
+			schema:     &base.Schema{Format: "int64", Minimum: float64Pointer(math.MaxInt64 / 2), Maximum: float64Pointer(math.MaxInt64)},
+			schemaType: "integer",
+			checker: func(value any) bool {
+				integer, ok := value.(int)
+				return ok && integer >= math.MaxInt64/2
+			},
+		},
+		"number with two decimals": {
+			schema:     &base.Schema{Minimum: float64Pointer(0.5), Maximum: float64Pointer(0.75)},
+			schemaType: "number",
+			checker: func(value any) bool {
+				number, ok := value.(float64)
+				return ok && number >= 0.5 && number <= 0.75 && roundTo(number, 2) == number
+			},
+		},
+		"float format": {
+			schema:     &base.Schema{Format: "float", Minimum: float64Pointer(0.1), Maximum: float64Pointer(0.2)},
+			schemaType: "number",
+			checker: func(value any) bool {
+				number, ok := value.(float64)
+				return ok && number >= 0.1 && number <= 0.2 && strconv.FormatFloat(number, 'g', -1, 64) == strconv.FormatFloat(number, 'g', -1, 32)
+			},
+		},
+		"float exclusive bounds": {
+			schema: &base.Schema{
+				Format:           "float",
+				ExclusiveMinimum: &base.DynamicValue[bool, float64]{N: 1, B: 1},
+				ExclusiveMaximum: &base.DynamicValue[bool, float64]{N: 1, B: 1.0000002},
+			},
+			schemaType: "number",
+			checker: func(value any) bool {
+				number, ok := value.(float64)
+				return ok && number > 1 && number < 1.0000002
+			},
+		},
+		"double format": {
+			schema:     &base.Schema{Format: "double", Minimum: float64Pointer(0.5), Maximum: float64Pointer(0.75)},
+			schemaType: "number",
+			checker: func(value any) bool {
+				number, ok := value.(float64)
+				return ok && number >= 0.5 && number <= 0.75
+			},
+		},
+		"double exclusive minimum": {
+			schema:     &base.Schema{Format: "double", ExclusiveMinimum: &base.DynamicValue[bool, float64]{N: 1, B: 0.001}, Maximum: float64Pointer(0.001)},
+			schemaType: "number",
+			checker: func(value any) bool {
+				return value == math.Nextafter(0.001, 1)
+			},
+		},
+		"number multiple of": {
+			schema:     &base.Schema{Minimum: float64Pointer(0), Maximum: float64Pointer(2), MultipleOf: float64Pointer(0.25)},
+			schemaType: "number",
+			checker: func(value any) bool {
+				number, ok := value.(float64)
+				return ok && math.Mod(number, 0.25) == 0
+			},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for range 50 {
+				// Act
+				result := numberValue(data.schema, data.schemaType, GenerateOptions{GenExamples: true})
+
+				// Assert
+				require.True(t, data.checker(result), "unexpected value %v", result)
+			}
+		})
+	}
+}
+
+func Test_arrayItems_RespectsConstraints(t *testing.T) {
+	t.Parallel()
+
+	int64Pointer := func(i int64) *int64 {
+		return &i
+	}
+	boolPointer := func(b bool) *bool {
+		return &b
+	}
+	tests := map[string]struct {
+		schema     *base.Schema
+		arrayItems string
+		checker    func([]any) bool
+	}{
+		"default item count": {
+			schema: &base.Schema{},
+			checker: func(items []any) bool {
+				return len(items) == 1
+			},
+		},
+		"global item range": {
+			schema:     &base.Schema{},
+			arrayItems: "2-4",
+			checker: func(items []any) bool {
+				return len(items) >= 2 && len(items) <= 4
+			},
+		},
+		"min items above range": {
+			schema:     &base.Schema{MinItems: int64Pointer(3)},
+			arrayItems: "1",
+			checker: func(items []any) bool {
+				return len(items) == 3
+			},
+		},
+		"max items below range": {
+			schema:     &base.Schema{MaxItems: int64Pointer(2)},
+			arrayItems: "5",
+			checker: func(items []any) bool {
+				return len(items) == 2
+			},
+		},
+		"unique items": {
+			schema:     &base.Schema{UniqueItems: boolPointer(true)},
+			arrayItems: "5",
+			checker: func(items []any) bool {
+				return len(items) == 3 && items[0] != items[1] && items[1] != items[2] && items[0] != items[2]
+			},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for range 50 {
+				// Arrange
+				calls := 0
+				generate := func() (any, bool) {
+					calls++
+					return calls % 3, true
+				}
+
+				// Act
+				result := arrayItems(data.schema, GenerateOptions{ArrayItems: data.arrayItems}, generate)
+
+				// Assert
+				require.True(t, data.checker(result), "unexpected items %v", result)
+			}
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_ReturnsArrayItemsError(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, err := SpecToRequestStructureMap("./testdata/examplev3.yaml", GenerateOptions{ArrayItems: "5-1"})
+
+	// Assert
+	require.EqualError(t, err, "invalid array item range '5-1', use a count like '3' or a range like '1-5'")
+}
+
 func Test_SpecV3toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 	t.Parallel()
 
@@ -187,7 +697,7 @@ func Test_SpecV3toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 								"id":           "",
 								"items":        []any{},
 								"status":       "",
-								"total_amount": nil,
+								"total_amount": float64(0),
 							},
 							RequestParams: []string{"orderId"},
 							RequestBody:   nil,
@@ -259,7 +769,7 @@ func Test_SpecV3toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 								"id":          "",
 								"image_url":   "",
 								"name":        "",
-								"price":       nil,
+								"price":       float64(0),
 								"stock":       0,
 								"updated_at":  "",
 							},
@@ -345,7 +855,7 @@ func Test_SpecV3toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 								"id":           "",
 								"items":        []any{},
 								"status":       "",
-								"total_amount": nil,
+								"total_amount": float64(0),
 							},
 							RequestParams: []string{},
 							RequestBody: map[string]any{
@@ -364,76 +874,76 @@ func Test_SpecV3toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 				"get": {
 					"/addresses": {
 						{
-							Path: "/addresses", Method: "get", Body: "", DbEntry: "addresses", ResponseCode: "200", ResponseBody: []map[string]any{
-								{
+							Path: "/addresses", Method: "get", Body: "", DbEntry: "addresses", ResponseCode: "200", ResponseBody: []any{
+								map[string]any{
 									"city": "", "country": "", "line1": "", "line2": "", "postal_code": "", "state": "",
 								},
 							}, RequestParams: []string{}, RequestBody: nil,
 						},
 					}, "/cart": {
 						{
-							Path: "/cart", Method: "get", Body: "", DbEntry: "cart", ResponseCode: "200", ResponseBody: []map[string]any{
-								{
+							Path: "/cart", Method: "get", Body: "", DbEntry: "cart", ResponseCode: "200", ResponseBody: []any{
+								map[string]any{
 									"product_id": "", "quantity": 1,
 								},
 							}, RequestParams: []string{}, RequestBody: nil,
 						},
 					}, "/orders": {
 						{
-							Path: "/orders", Method: "get", Body: "", DbEntry: "orders", ResponseCode: "200", ResponseBody: []map[string]any{
-								{
-									"created_at": "", "id": "", "items": []any{}, "status": "", "total_amount": nil,
+							Path: "/orders", Method: "get", Body: "", DbEntry: "orders", ResponseCode: "200", ResponseBody: []any{
+								map[string]any{
+									"created_at": "", "id": "", "items": []any{}, "status": "", "total_amount": float64(0),
 								},
 							}, RequestParams: []string{}, RequestBody: nil,
 						},
 					}, "/orders/:orderId": {
 						{
 							Path: "/orders/:orderId", Method: "get", Body: "", DbEntry: "orders", ResponseCode: "200", ResponseBody: map[string]any{
-								"created_at": "", "id": "", "items": []map[string]any{
-									{
+								"created_at": "", "id": "", "items": []any{
+									map[string]any{
 										"product_id": "", "quantity": 1,
 									},
-								}, "status": "", "total_amount": nil,
+								}, "status": "", "total_amount": float64(0),
 							}, RequestParams: []string{
 								"orderId",
 							}, RequestBody: nil,
 						},
 					}, "/products": {
 						{
-							Path: "/products", Method: "get", Body: "", DbEntry: "products", ResponseCode: "200", ResponseBody: []map[string]any{
-								{
-									"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": nil, "stock": 0, "updated_at": "",
+							Path: "/products", Method: "get", Body: "", DbEntry: "products", ResponseCode: "200", ResponseBody: []any{
+								map[string]any{
+									"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": float64(0), "stock": 0, "updated_at": "",
 								},
 							}, RequestParams: []string{}, RequestBody: nil,
 						}, {
-							Path: "/products?category=", Method: "get", Body: "", DbEntry: "products", ResponseCode: "200", ResponseBody: []map[string]any{
-								{
-									"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": nil, "stock": 0, "updated_at": "",
+							Path: "/products?category=", Method: "get", Body: "", DbEntry: "products", ResponseCode: "200", ResponseBody: []any{
+								map[string]any{
+									"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": float64(0), "stock": 0, "updated_at": "",
 								},
 							}, RequestParams: []string{}, RequestBody: nil,
 						}, {
-							Path: "/products?search=", Method: "get", Body: "", DbEntry: "products", ResponseCode: "200", ResponseBody: []map[string]any{
-								{
-									"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": nil, "stock": 0, "updated_at": "",
+							Path: "/products?search=", Method: "get", Body: "", DbEntry: "products", ResponseCode: "200", ResponseBody: []any{
+								map[string]any{
+									"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": float64(0), "stock": 0, "updated_at": "",
 								},
 							}, RequestParams: []string{}, RequestBody: nil,
 						}, {
-							Path: "/products?min_price=", Method: "get", Body: "", DbEntry: "products", ResponseCode: "200", ResponseBody: []map[string]any{
-								{
-									"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": nil, "stock": 0, "updated_at": "",
+							Path: "/products?min_price=", Method: "get", Body: "", DbEntry: "products", ResponseCode: "200", ResponseBody: []any{
+								map[string]any{
+									"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": float64(0), "stock": 0, "updated_at": "",
 								},
 							}, RequestParams: []string{}, RequestBody: nil,
 						}, {
-							Path: "/products?max_price=", Method: "get", Body: "", DbEntry: "products", ResponseCode: "200", ResponseBody: []map[string]any{
-								{
-									"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": nil, "stock": 0, "updated_at": "",
+							Path: "/products?max_price=", Method: "get", Body: "", DbEntry: "products", ResponseCode: "200", ResponseBody: []any{
+								map[string]any{
+									"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": float64(0), "stock": 0, "updated_at": "",
 								},
 							}, RequestParams: []string{}, RequestBody: nil,
 						},
 					}, "/products/:id": {
 						{
 							Path: "/products/:id", Method: "get", Body: "", DbEntry: "products", ResponseCode: "200", ResponseBody: map[string]any{
-								"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": nil, "stock": 0, "updated_at": "",
+								"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": float64(0), "stock": 0, "updated_at": "",
 							}, RequestParams: []string{
 								"id",
 							}, RequestBody: nil,
@@ -467,11 +977,11 @@ func Test_SpecV3toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 					}, "/checkout": {
 						{
 							Path: "/checkout", Method: "post", Body: "", DbEntry: "checkout", ResponseCode: "201", ResponseBody: map[string]any{
-								"created_at": "", "id": "", "items": []map[string]any{
-									{
+								"created_at": "", "id": "", "items": []any{
+									map[string]any{
 										"product_id": "", "quantity": 1,
 									},
-								}, "status": "", "total_amount": nil,
+								}, "status": "", "total_amount": float64(0),
 							}, RequestParams: []string{}, RequestBody: map[string]any{
 								"address_id": "", "payment_method_id": "",
 							},
@@ -523,14 +1033,14 @@ func Test_SpecV2toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 					DbEntry:      "products",
 					ResponseCode: "200",
 					ResponseBody: map[string]any{
-						"items": []map[string]any{
-							{
+						"items": []any{
+							map[string]any{
 								"description": "",
 								"id":          "",
 								"metadata":    nil,
 								"name":        "",
-								"price":       nil,
-								"tags":        []any{},
+								"price":       float64(0),
+								"tags":        []any{""},
 							},
 						},
 						"page":       0,
@@ -547,14 +1057,14 @@ func Test_SpecV2toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 					DbEntry:      "products",
 					ResponseCode: "200",
 					ResponseBody: map[string]any{
-						"items": []map[string]any{
-							{
+						"items": []any{
+							map[string]any{
 								"description": "",
 								"id":          "",
 								"metadata":    nil,
 								"name":        "",
-								"price":       nil,
-								"tags":        []any{},
+								"price":       float64(0),
+								"tags":        []any{""},
 							},
 						},
 						"page":       0,
@@ -571,14 +1081,14 @@ func Test_SpecV2toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 					DbEntry:      "products",
 					ResponseCode: "200",
 					ResponseBody: map[string]any{
-						"items": []map[string]any{
-							{
+						"items": []any{
+							map[string]any{
 								"description": "",
 								"id":          "",
 								"metadata":    nil,
 								"name":        "",
-								"price":       nil,
-								"tags":        []any{},
+								"price":       float64(0),
+								"tags":        []any{""},
 							},
 						},
 						"page":       0,
@@ -602,9 +1112,9 @@ func Test_SpecV2toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 						"id":          "",
 						"metadata":    nil,
 						"name":        "",
-						"price":       nil,
-						"tags": []map[string]any{
-							{},
+						"price":       float64(0),
+						"tags": []any{
+							"",
 						},
 					},
 					RequestParams: []string{"productId"},
@@ -619,12 +1129,12 @@ func Test_SpecV2toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 					Body:         "",
 					DbEntry:      "users-orders",
 					ResponseCode: "200",
-					ResponseBody: []map[string]any{
-						{
+					ResponseBody: []any{
+						map[string]any{
 							"id":         "",
 							"items":      []any{},
 							"status":     "",
-							"totalPrice": nil,
+							"totalPrice": float64(0),
 							"userId":     "",
 						},
 					},
@@ -637,12 +1147,12 @@ func Test_SpecV2toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 					Body:         "",
 					DbEntry:      "users-orders",
 					ResponseCode: "200",
-					ResponseBody: []map[string]any{
-						{
+					ResponseBody: []any{
+						map[string]any{
 							"id":         "",
 							"items":      []any{},
 							"status":     "",
-							"totalPrice": nil,
+							"totalPrice": float64(0),
 							"userId":     "",
 						},
 					},
@@ -665,9 +1175,9 @@ func Test_SpecV2toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 						"id":          "",
 						"metadata":    nil,
 						"name":        "",
-						"price":       nil,
-						"tags": []map[string]any{
-							{},
+						"price":       float64(0),
+						"tags": []any{
+							"",
 						},
 					},
 					RequestParams: []string{},
@@ -685,23 +1195,862 @@ func Test_SpecV2toRequestStructureMap_ReturnsResponseBody(t *testing.T) {
 	assert.Equal(t, expectedMap, resultMap)
 }
 
-func Test_GenerateDbFile_ReturnsContent(t *testing.T) {
+func Test_SpecToRequestStructureMap_DetectsSpecVersion(t *testing.T) {
 	t.Parallel()
 
-	// Arrange
-	featureFileDataStructure, parseErr := SpecV3toRequestStructureMap("./testdata/examplev3.yaml", 1, false)
-	expectedResult, readErr := os.ReadFile("./testdata/examplev3-result/db.json")
+	tests := map[string]struct {
+		specFile     string
+		opts         GenerateOptions
+		expectedFunc func(string, int, bool) (map[string]map[string][]RequestStructure, error)
+	}{
+		"detects v2": {
+			specFile:     "./testdata/examplev2.yaml",
+			opts:         GenerateOptions{MaxRecursionDepth: 1},
+			expectedFunc: SpecV2toRequestStructureMap,
+		},
+		"detects v3": {
+			specFile:     "./testdata/examplev3.yaml",
+			opts:         GenerateOptions{MaxRecursionDepth: 1},
+			expectedFunc: SpecV3toRequestStructureMap,
+		},
+		"matching override": {
+			specFile:     "./testdata/examplev3.yaml",
+			opts:         GenerateOptions{SpecMajorVersion: 3, MaxRecursionDepth: 1},
+			expectedFunc: SpecV3toRequestStructureMap,
+		},
+		"override of an undetected version": {
+			specFile:     "./testdata/exampleunsupported.yaml",
+			opts:         GenerateOptions{SpecMajorVersion: 2, MaxRecursionDepth: 1},
+			expectedFunc: SpecV2toRequestStructureMap,
+		},
+	}
 
-	// Act
-	result, genErr := GenerateDbFile(featureFileDataStructure)
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
-	// Assert
+			// Arrange
+			expectedMap, expectedErr := data.expectedFunc(data.specFile, data.opts.MaxRecursionDepth, data.opts.GenExamples)
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap(data.specFile, data.opts)
+
+			// Assert
+			require.NoError(t, expectedErr)
+			require.NoError(t, err)
+			assert.Equal(t, expectedMap, resultMap)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_ReturnsVersionError(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		specFile      string
+		opts          GenerateOptions
+		expectedError string
+	}{
+		"unsupported version": {
+			specFile:      "./testdata/exampleunsupported.yaml",
+			opts:          GenerateOptions{},
+			expectedError: "unsupported spec version 'swagger: 1.2', only 'swagger: 2.x' and 'openapi: 3.x' are supported",
+		},
+		"mismatching override": {
+			specFile:      "./testdata/examplev3.yaml",
+			opts:          GenerateOptions{SpecMajorVersion: 2},
+			expectedError: "cannot read the spec as a version 2 spec: unable to build swagger document, supplied spec is a different version (oas3_1). Try 'BuildV3Model()'",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			_, err := SpecToRequestStructureMap(data.specFile, data.opts)
+
+			// Assert
+			assert.EqualError(t, err, data.expectedError)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_SupportsOpenAPI31(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		opts         GenerateOptions
+		expectedBody map[string]any
+	}{
+		"non-null branch": {
+			opts: GenerateOptions{MaxRecursionDepth: 1},
+			expectedBody: map[string]any{
+				"kind":       "truck",
+				"nickname":   "",
+				"retired_at": "",
+				"position": map[string]any{
+					"label": "",
+				},
+				"route": []any{"", 3, false},
+				"tags": []any{
+					"",
+				},
+				"axles": 2,
+			},
+		},
+		"prefer null": {
+			opts: GenerateOptions{MaxRecursionDepth: 1, PreferNull: true},
+			expectedBody: map[string]any{
+				"kind":       "truck",
+				"nickname":   nil,
+				"retired_at": nil,
+				"position": map[string]any{
+					"label": "",
+				},
+				"route": []any{"", 3, false},
+				"tags": []any{
+					"",
+				},
+				"axles": 2,
+			},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap("./testdata/examplev31.yaml", data.opts)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, data.expectedBody, resultMap["get"]["/vehicles/:vehicleId"][0].ResponseBody)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_UsesOpenAPI31Examples(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	resultMap, err := SpecToRequestStructureMap("./testdata/examplev31.yaml", GenerateOptions{MaxRecursionDepth: 1, GenExamples: true})
+
+	// Assert
+	require.NoError(t, err)
+	responseBody, ok := resultMap["get"]["/vehicles/:vehicleId"][0].ResponseBody.(map[string]any)
+	require.True(t, ok)
+	assert.Equal(t, "truck", responseBody["kind"])
+	assert.Equal(t, []any{"refrigerated", "long-haul"}, responseBody["tags"])
+}
+
+func Test_SpecToRequestStructureMap_SelectsUnionBranch(t *testing.T) {
+	t.Parallel()
+
+	card := map[string]any{"type": "card", "last4": ""}
+	iban := map[string]any{"type": "iban", "iban": ""}
+	tests := map[string]struct {
+		opts             GenerateOptions
+		expectedBody     any
+		expectedVariants []any
+		expectedPayment  any
+	}{
+		"first branch": {
+			opts:         GenerateOptions{MaxRecursionDepth: 1},
+			expectedBody: card,
+			expectedPayment: map[string]any{
+				"id":        "",
+				"method":    card,
+				"reference": 7,
+			},
+		},
+		"configured branch": {
+			opts:         GenerateOptions{MaxRecursionDepth: 1, UnionBranch: "1"},
+			expectedBody: iban,
+			expectedPayment: map[string]any{
+				"id":        "",
+				"method":    iban,
+				"reference": "",
+			},
+		},
+		"all branches": {
+			opts:             GenerateOptions{MaxRecursionDepth: 1, AllBranches: true},
+			expectedBody:     card,
+			expectedVariants: []any{card, iban},
+			expectedPayment: map[string]any{
+				"id":        "",
+				"method":    card,
+				"reference": 7,
+			},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap("./testdata/examplepolymorphic.yaml", data.opts)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, data.expectedBody, resultMap["get"]["/payment-methods/:paymentMethodId"][0].ResponseBody)
+			assert.Equal(t, data.expectedVariants, resultMap["get"]["/payment-methods/:paymentMethodId"][0].ResponseVariants)
+			assert.Equal(t, data.expectedPayment, resultMap["post"]["/payments"][0].ResponseBody)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_ValidatesUnionBranch(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		specFile      string
+		opts          GenerateOptions
+		expectedError string
+	}{
+		"word":          {specFile: "./testdata/examplepolymorphic.yaml", opts: GenerateOptions{UnionBranch: "foo"}, expectedError: "invalid union branch 'foo', use first, random or the index of a branch"},
+		"negative":      {specFile: "./testdata/examplepolymorphic.yaml", opts: GenerateOptions{UnionBranch: "-1"}, expectedError: "invalid union branch '-1', use first, random or the index of a branch"},
+		"out of range":  {specFile: "./testdata/examplepolymorphic.yaml", opts: GenerateOptions{UnionBranch: "2"}, expectedError: "union branch 2 is out of range, the schema has 2 branches"},
+		"typed schema":  {specFile: "./testdata/exampleuniontyped.yaml", opts: GenerateOptions{UnionBranch: "5"}},
+		"index zero":    {specFile: "./testdata/examplepolymorphic.yaml", opts: GenerateOptions{UnionBranch: "0"}},
+		"random branch": {specFile: "./testdata/examplepolymorphic.yaml", opts: GenerateOptions{UnionBranch: "random"}},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			_, err := SpecToRequestStructureMap(data.specFile, data.opts)
+
+			// Assert
+			if data.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, data.expectedError)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_IgnoresUnionOfTypedSchema(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	resultMap, err := SpecToRequestStructureMap("./testdata/exampleuniontyped.yaml", GenerateOptions{AllBranches: true})
+
+	// Assert
+	require.NoError(t, err)
+	require.NotEmpty(t, resultMap["get"]["/codes/:codeId"])
+	assert.Equal(t, map[string]any{"code": ""}, resultMap["get"]["/codes/:codeId"][0].ResponseBody)
+	assert.Nil(t, resultMap["get"]["/codes/:codeId"][0].ResponseVariants)
+}
+
+func Test_SpecToRequestStructureMap_UsesSpecExamples(t *testing.T) {
+	t.Parallel()
+
+	novel := map[string]any{"id": "b-1", "title": "Dune", "pages": 412}
+	poetry := map[string]any{"id": "b-2", "title": "Leaves of Grass", "pages": 145}
+	tests := map[string]struct {
+		opts             GenerateOptions
+		method           string
+		path             string
+		expectedBody     any
+		expectedExamples map[string]any
+	}{
+		"named media type examples": {
+			opts:             GenerateOptions{MaxRecursionDepth: 1, GenExamples: true},
+			method:           "get",
+			path:             "/books/:id",
+			expectedBody:     novel,
+			expectedExamples: map[string]any{"novel": novel, "poetry": poetry},
+		},
+		"selected media type example": {
+			opts:             GenerateOptions{MaxRecursionDepth: 1, GenExamples: true, ExampleName: "poetry"},
+			method:           "get",
+			path:             "/books/:id",
+			expectedBody:     poetry,
+			expectedExamples: map[string]any{"novel": novel, "poetry": poetry},
+		},
+		"single media type example": {
+			opts:         GenerateOptions{MaxRecursionDepth: 1, GenExamples: true},
+			method:       "get",
+			path:         "/books",
+			expectedBody: []any{novel},
+		},
+		"schema examples and defaults": {
+			opts:   GenerateOptions{MaxRecursionDepth: 1, GenExamples: true},
+			method: "get",
+			path:   "/authors/:id",
+			expectedBody: map[string]any{
+				"id":       "a-42",
+				"name":     "Frank Herbert",
+				"country":  "unknown",
+				"books":    3,
+				"genre":    "science fiction",
+				"language": "en",
+				"address":  map[string]any{"street": "Main Street 1"},
+			},
+		},
+		"defaults without examples": {
+			opts:   GenerateOptions{MaxRecursionDepth: 1},
+			method: "get",
+			path:   "/authors/:id",
+			expectedBody: map[string]any{
+				"id":       "",
+				"name":     "",
+				"country":  "unknown",
+				"books":    3,
+				"genre":    "fiction",
+				"language": "en",
+				"address":  map[string]any{"street": ""},
+			},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap("./testdata/exampleexamples.yaml", data.opts)
+
+			// Assert
+			require.NoError(t, err)
+			result := resultMap[data.method][data.path][0]
+			assert.Equal(t, data.expectedBody, result.ResponseBody)
+			assert.Equal(t, data.expectedExamples, result.ResponseExamples)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_ResolvesExternalReferences(t *testing.T) {
+	t.Parallel()
+
+	user := map[string]any{
+		"id":        "",
+		"name":      "",
+		"address":   map[string]any{"city": ""},
+		"lastError": map[string]any{"code": 0, "message": ""},
+	}
+	tests := map[string]struct {
+		specFilename string
+		path         string
+		expectedBody any
+	}{
+		"v3 schema in another file": {
+			specFilename: "./testdata/examplemultifile/api/openapi.yaml",
+			path:         "/users/:id",
+			expectedBody: user,
+		},
+		"v3 response component with external items": {
+			specFilename: "./testdata/examplemultifile/api/openapi.yaml",
+			path:         "/users",
+			expectedBody: []any{user},
+		},
+		"v2 definition in another file": {
+			specFilename: "./testdata/examplemultifilev2/swagger.yaml",
+			path:         "/api/items/:id",
+			expectedBody: map[string]any{"id": "", "quantity": 0, "status": "in-stock"},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap(data.specFilename, GenerateOptions{MaxRecursionDepth: 2})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, data.expectedBody, resultMap["get"][data.path][0].ResponseBody)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_ReadsSpecOutsideWorkingDirectory(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	specDir := t.TempDir()
+	require.NoError(t, os.CopyFS(specDir, os.DirFS("./testdata/examplemultifile")))
+
+	// Act
+	resultMap, err := SpecToRequestStructureMap(filepath.Join(specDir, "api", "openapi.yaml"), GenerateOptions{MaxRecursionDepth: 2})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"id":        "",
+		"name":      "",
+		"address":   map[string]any{"city": ""},
+		"lastError": map[string]any{"code": 0, "message": ""},
+	}, resultMap["get"]["/users/:id"][0].ResponseBody)
+}
+
+func Test_SpecToRequestStructureMap_ReturnsMissingReferenceError(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, err := SpecToRequestStructureMap("./testdata/examplemultifile/api/broken.yaml", GenerateOptions{})
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "#/User")
+}
+
+func Test_SpecToRequestStructureMap_GeneratesAdditionalProperties(t *testing.T) {
+	t.Parallel()
+
+	quota := map[string]any{"limit": 10}
+	tests := map[string]struct {
+		mapKeys          int
+		expectedQuotas   map[string]any
+		expectedLabels   int
+		expectedFeatures map[string]any
+	}{
+		"default key count": {
+			mapKeys:          0,
+			expectedQuotas:   map[string]any{"key1": quota},
+			expectedLabels:   2,
+			expectedFeatures: map[string]any{"version": "", "alpha": false},
+		},
+		"configured key count": {
+			mapKeys:          3,
+			expectedQuotas:   map[string]any{"key1": quota, "key2": quota, "key3": quota},
+			expectedLabels:   3,
+			expectedFeatures: map[string]any{"version": "", "alpha": false, "beta": false},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap("./testdata/examplemaps.yaml", GenerateOptions{MaxRecursionDepth: 2, MapKeys: data.mapKeys})
+
+			// Assert
+			require.NoError(t, err)
+			responseBody, ok := resultMap["get"]["/tenants/:id"][0].ResponseBody.(map[string]any)
+			require.True(t, ok)
+			assert.Equal(t, data.expectedQuotas, responseBody["quotas"])
+			assert.Equal(t, data.expectedFeatures, responseBody["features"])
+			labels, ok := responseBody["labels"].(map[string]any)
+			require.True(t, ok)
+			assert.Len(t, labels, data.expectedLabels)
+			for key, value := range labels {
+				assert.Regexp(t, `^[a-z]{3}-[0-9]$`, key)
+				assert.Equal(t, "", value)
+			}
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_GeneratesAdditionalPropertiesV2(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	resultMap, err := SpecToRequestStructureMap("./testdata/examplemapsv2.yaml", GenerateOptions{MaxRecursionDepth: 1, MapKeys: 2})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"name": "",
+		"key1": map[string]any{"limit": 10},
+		"key2": map[string]any{"limit": 10},
+	}, resultMap["get"]["/tenants/:id"][0].ResponseBody)
+}
+
+func Test_SpecToRequestStructureMap_UsesMockExtensions(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		specFilename string
+		path         string
+		expectedTags []any
+	}{
+		"v3": {
+			specFilename: "./testdata/exampleextensions.yaml",
+			path:         "/companies/:id",
+			expectedTags: []any{"tag", "tag", "tag"},
+		},
+		"v2": {
+			specFilename: "./testdata/exampleextensionsv2.yaml",
+			path:         "/companies/:id",
+			expectedTags: []any{"tag", "tag"},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap(data.specFilename, GenerateOptions{MaxRecursionDepth: 1})
+
+			// Assert
+			require.NoError(t, err)
+			responseBody, ok := resultMap["get"][data.path][0].ResponseBody.(map[string]any)
+			require.True(t, ok)
+			assert.Contains(t, fakedata.Company["name"], responseBody["name"])
+			assert.Equal(t, 42, responseBody["employees"])
+			assert.Equal(t, data.expectedTags, responseBody["tags"])
+			assert.Equal(t, "1999-12-31", responseBody["founded"])
+			_, err = mail.ParseAddress(fmt.Sprint(responseBody["contact"]))
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_ReturnsUnknownFakerError(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	_, err := SpecToRequestStructureMap("./testdata/exampleunknownfaker.yaml", GenerateOptions{})
+
+	// Assert
+	require.Error(t, err)
+	assert.EqualError(t, err, "unknown faker 'company.motto' in x-faker, did you mean job, blurb, bs, jobtitle, slogan?")
+}
+
+func Test_fakerLookup_SuggestsClosestNames(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		fakerName   string
+		expectedErr string
+	}{
+		"typo": {
+			fakerName:   "emial",
+			expectedErr: "unknown faker 'emial' in x-faker, did you mean email, animal, ein, emoji, map?",
+		},
+		"typo in a category": {
+			fakerName:   "person.fristName",
+			expectedErr: "unknown faker 'person.fristName' in x-faker, did you mean firstname, lastname, name, middlename, teams?",
+		},
+		"unknown category": {
+			fakerName:   "pet.adress",
+			expectedErr: "unknown faker 'pet.adress' in x-faker, did you mean address, ipv4address, ipv6address, macaddress, petname?",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			_, err := fakerLookup(data.fakerName)
+
+			// Assert
+			assert.EqualError(t, err, data.expectedErr)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_IsDeterministicWithSeed(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		specFilename string
+	}{
+		"v2": {
+			specFilename: "./testdata/examplev2.yaml",
+		},
+		"v3": {
+			specFilename: "./testdata/examplev3.yaml",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			generate := func(seed uint64) string {
+				resultMap, err := SpecToRequestStructureMap(data.specFilename, GenerateOptions{MaxRecursionDepth: 2, GenExamples: true, ArrayItems: "1-5", Seed: seed})
+				require.NoError(t, err)
+				serverFile, err := GenerateServerFile("http", 5000, "db.json", resultMap)
+				require.NoError(t, err)
+
+				return serverFile
+			}
+
+			// Act
+			first := generate(42)
+			second := generate(42)
+			other := generate(43)
+
+			// Assert
+			assert.Equal(t, first, second)
+			assert.NotEqual(t, first, other)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_CapturesAllResponses(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		specFilename  string
+		expectedCodes []string
+	}{
+		"v2": {
+			specFilename:  "./testdata/examplestatusesv2.yaml",
+			expectedCodes: []string{"200", "404", "default"},
+		},
+		"v3": {
+			specFilename:  "./testdata/examplestatuses.yaml",
+			expectedCodes: []string{"200", "404", "5XX", "default"},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap(data.specFilename, GenerateOptions{MaxRecursionDepth: 1, GenExamples: true, AllResponses: true})
+
+			// Assert
+			require.NoError(t, err)
+			require.Len(t, resultMap["get"]["/pets/:petId"], 1)
+			responses := resultMap["get"]["/pets/:petId"][0].Responses
+			assert.Equal(t, data.expectedCodes, slices.Sorted(maps.Keys(responses)))
+			assert.Equal(t, map[string]any{"code": 404, "message": "pet not found"}, responses["404"])
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_GeneratesResponseHeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		specFilename string
+	}{
+		"v2": {
+			specFilename: "./testdata/exampleheadersv2.yaml",
+		},
+		"v3": {
+			specFilename: "./testdata/exampleheaders.yaml",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap(data.specFilename, GenerateOptions{MaxRecursionDepth: 1, GenExamples: true, AllResponses: true})
+
+			// Assert
+			require.NoError(t, err)
+			require.Len(t, resultMap["get"]["/pets"], 1)
+			require.Len(t, resultMap["post"]["/pets"], 1)
+			assert.Equal(t, map[string]string{"ETag": `"8ff3ee16be0d37f7"`, "X-RateLimit-Remaining": "99", "X-Total-Count": "2"}, resultMap["get"]["/pets"][0].ResponseHeaders)
+			assert.Equal(t, map[string]string{"Location": "/pets/3"}, resultMap["post"]["/pets"][0].ResponseHeaders)
+			assert.Equal(t, map[string]map[string]string{"201": {"Location": "/pets/3"}, "429": {"Retry-After": "30"}}, resultMap["post"]["/pets"][0].StatusHeaders)
+		})
+	}
+}
+
+func Test_GenerateServerFile_SetsResponseHeaders(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/exampleheaders.yaml", GenerateOptions{MaxRecursionDepth: 1, GenExamples: true})
+	require.NoError(t, err)
+
+	// Act
+	result, err := GenerateServerFile("http", 5000, "db.json", featureFileDataStructure)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, result, `responseHeaders = {"Location":"/pets/3"};
+	res.set(responseHeaders);
+	checkWriteToDb();`)
+	assert.Contains(t, result, `responseHeaders = {"ETag":"\"8ff3ee16be0d37f7\"","X-RateLimit-Remaining":"99","X-Total-Count":"2"};`)
+}
+
+func Test_GenerateServerFile_SetsSeededResponseHeaders(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/exampledbheaders.yaml", GenerateOptions{MaxRecursionDepth: 1, GenExamples: true, DbRecords: 3})
+	require.NoError(t, err)
+
+	// Act
+	result, err := GenerateServerFile("http", 5000, "db.json", featureFileDataStructure)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, result, `responseBody = seededRecords(req, 'pets', {}, '');`)
+	assert.Contains(t, result, `res.set(crudHeaders(responseHeaders, responseBody, '/pets'));`)
+	assert.Contains(t, result, `res.set(crudHeaders(responseHeaders, responseBody, '/pets/:petId'));`)
+}
+
+func Test_GenerateDbFile_ReturnsContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, parseErr := SpecV3toRequestStructureMap("./testdata/examplev3.yaml", 1, false)
+	expectedResult, readErr := os.ReadFile("./testdata/examplev3-result/db.json")
+
+	// Act
+	result, genErr := GenerateDbFile(featureFileDataStructure)
+
+	// Assert
 	require.NoError(t, parseErr)
 	require.NoError(t, readErr)
 	require.NoError(t, genErr)
 	assert.Equal(t, string(expectedResult), result)
 }
 
+func Test_GenerateDbFile_SeedsRecords(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		specFilename string
+		collection   string
+		expectedIds  []any
+		idParam      string
+	}{
+		"v2 list response": {
+			specFilename: "./testdata/examplev2.yaml",
+			collection:   "products",
+			expectedIds:  []any{"1", "2", "3"},
+		},
+		"v3 item response": {
+			specFilename: "./testdata/examplev3.yaml",
+			collection:   "orders",
+			expectedIds:  []any{"1", "2", "3"},
+		},
+		"v3 path parameter": {
+			specFilename: "./testdata/exampledbrecords.yaml",
+			collection:   "pets",
+			expectedIds:  []any{float64(1), float64(2), float64(3)},
+			idParam:      "petId",
+		},
+		"v3 list without item route": {
+			specFilename: "./testdata/exampledbrecords.yaml",
+			collection:   "owners",
+			expectedIds:  []any{"1", "2", "3"},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			featureFileDataStructure, err := SpecToRequestStructureMap(data.specFilename, GenerateOptions{MaxRecursionDepth: 1, GenExamples: true, DbRecords: 3})
+			require.NoError(t, err)
+
+			// Act
+			result, err := GenerateDbFile(featureFileDataStructure)
+
+			// Assert
+			require.NoError(t, err)
+			var db map[string][]map[string]any
+			require.NoError(t, json.Unmarshal([]byte(result), &db))
+			ids := []any{}
+			for _, record := range db[data.collection] {
+				ids = append(ids, record["id"])
+				if data.idParam != "" {
+					assert.Equal(t, record["id"], record[data.idParam])
+				}
+			}
+			assert.Equal(t, data.expectedIds, ids)
+		})
+	}
+}
+
+func Test_GenerateDbFile_LinksSeededRecords(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		collection     string
+		field          string
+		expectedValues []any
+	}{
+		"entity id property": {
+			collection:     "orders",
+			field:          "customerId",
+			expectedValues: []any{float64(1), float64(2), float64(3)},
+		},
+		"x-mock-ref extension": {
+			collection:     "orders",
+			field:          "productCode",
+			expectedValues: []any{"1", "2", "3"},
+		},
+		"response link": {
+			collection:     "orders",
+			field:          "supplier",
+			expectedValues: []any{"1", "2", "3"},
+		},
+		"nested route parent": {
+			collection:     "customers-orders",
+			field:          "customerId",
+			expectedValues: []any{float64(1), float64(2), float64(3)},
+		},
+		"nested route x-mock-ref extension": {
+			collection:     "customers-orders",
+			field:          "productCode",
+			expectedValues: []any{"1", "2", "3"},
+		},
+		"nested route response link": {
+			collection:     "customers-orders",
+			field:          "supplier",
+			expectedValues: []any{"1", "2", "3"},
+		},
+	}
+
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/exampledbrelations.yaml", GenerateOptions{MaxRecursionDepth: 1, DbRecords: 3})
+	require.NoError(t, err)
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			var db map[string][]map[string]any
+
+			// Act
+			result, err := GenerateDbFile(featureFileDataStructure)
+
+			// Assert
+			require.NoError(t, err)
+			require.NoError(t, json.Unmarshal([]byte(result), &db))
+			values := []any{}
+			for _, record := range db[data.collection] {
+				values = append(values, record[data.field])
+			}
+			assert.Equal(t, data.expectedValues, values)
+		})
+	}
+}
+
+func Test_GenerateDbFile_KeepsFieldsWithoutForeignKey(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/exampledbroot.yaml", GenerateOptions{MaxRecursionDepth: 1, DbRecords: 2})
+	require.NoError(t, err)
+	var db map[string][]map[string]any
+
+	// Act
+	result, err := GenerateDbFile(featureFileDataStructure)
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal([]byte(result), &db))
+	require.Len(t, db[""], 2)
+	assert.Equal(t, []map[string]any{{"id": float64(1), "name": "Rex", "age": float64(7)}, {"id": float64(2), "name": "Rex", "age": float64(7)}}, db["pets"])
+}
+
 func Test_GenerateServerFile_ReturnsContent(t *testing.T) {
 	t.Parallel()
 
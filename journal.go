@@ -0,0 +1,342 @@
+package genmock
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+type RecordedRequest struct {
+	Method      string            `json:"method"`
+	Path        string            `json:"path"`
+	Query       string            `json:"query"`
+	Url         string            `json:"url"`
+	Params      map[string]string `json:"params,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        string            `json:"body"`
+	Route       string            `json:"route,omitempty"`
+	OperationId string            `json:"operationId,omitempty"`
+	Status      int               `json:"status"`
+	Timestamp   time.Time         `json:"timestamp"`
+}
+
+type RequestMatcher struct {
+	Method      string            `json:"method,omitempty"`
+	Path        string            `json:"path,omitempty"`
+	OperationId string            `json:"operationId,omitempty"`
+	Query       map[string]string `json:"query,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Body        any               `json:"body,omitempty"`
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (sr *statusRecorder) WriteHeader(statusCode int) {
+	if sr.statusCode == 0 {
+		sr.statusCode = statusCode
+	}
+	sr.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (sr *statusRecorder) Write(content []byte) (int, error) {
+	if sr.statusCode == 0 {
+		sr.statusCode = http.StatusOK
+	}
+	return sr.ResponseWriter.Write(content)
+}
+
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (sr *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := sr.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+func (s *Server) Requests() []RecordedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+func (s *Server) FindRequests(matcher RequestMatcher) []RecordedRequest {
+	requests := []RecordedRequest{}
+	for _, request := range s.Requests() {
+		if matcher.Matches(request) {
+			requests = append(requests, request)
+		}
+	}
+
+	return requests
+}
+
+func (s *Server) CountRequests(matcher RequestMatcher) int {
+	return len(s.FindRequests(matcher))
+}
+
+func (s *Server) VerifyRequests(matcher RequestMatcher, count int) error {
+	return verifyCount(matcher, count, s.CountRequests(matcher))
+}
+
+func (s *Server) ClearRequests() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = nil
+}
+
+func (s *Server) ExportRequests(format string) ([]byte, error) {
+	requests := append([]RecordedRequest{}, s.Requests()...)
+	switch format {
+	case "", "json":
+		return json.MarshalIndent(requests, "", "  ")
+	case "har":
+		return json.MarshalIndent(harLog(requests), "", "  ")
+	}
+
+	return nil, fmt.Errorf("invalid format '%s', use json or har", format)
+}
+
+func (s *Server) newRecord(r *http.Request, matchedRoute *route, pathParams []string) RecordedRequest {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		body = []byte{}
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	headers := map[string]string{"host": r.Host}
+	for name, values := range r.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ", ")
+	}
+	recorded := RecordedRequest{
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		Query:     r.URL.RawQuery,
+		Url:       fmt.Sprintf("%s://%s%s", scheme, r.Host, r.URL.RequestURI()),
+		Headers:   headers,
+		Body:      string(body),
+		Timestamp: time.Now().UTC(),
+	}
+	if matchedRoute != nil && matchedRoute.method == strings.ToLower(r.Method) {
+		recorded.Route = fmt.Sprintf("%s %s", r.Method, strings.Split(matchedRoute.request.Path, "?")[0])
+		recorded.OperationId = matchedRoute.request.OperationId
+		params := map[string]string{}
+		for _, segment := range matchedRoute.segments {
+			if name, ok := strings.CutPrefix(segment, ":"); ok && len(params) < len(pathParams) {
+				params[name] = pathParams[len(params)]
+			}
+		}
+		if len(params) > 0 {
+			recorded.Params = params
+		}
+	}
+
+	return recorded
+}
+
+func (s *Server) record(recorded RecordedRequest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, recorded)
+}
+
+func (matcher RequestMatcher) Matches(request RecordedRequest) bool {
+	if matcher.Method != "" && !strings.EqualFold(matcher.Method, request.Method) {
+		return false
+	}
+	if matcher.OperationId != "" && matcher.OperationId != request.OperationId {
+		return false
+	}
+	if matcher.Path != "" {
+		pattern := splitPath(pathParamPattern.ReplaceAllString(matcher.Path, ":$1"))
+		if _, _, ok := matchSegments(pattern, splitPath(request.Path)); !ok {
+			return false
+		}
+	}
+	if len(matcher.Query) > 0 {
+		query, err := url.ParseQuery(request.Query)
+		if err != nil {
+			return false
+		}
+		for name, value := range matcher.Query {
+			if values, ok := query[name]; !ok || values[0] != value {
+				return false
+			}
+		}
+	}
+	for name, value := range matcher.Headers {
+		if actual, ok := request.Headers[strings.ToLower(name)]; !ok || actual != value {
+			return false
+		}
+	}
+	if matcher.Body != nil && !bodyMatches(matcher.Body, request.Body) {
+		return false
+	}
+
+	return true
+}
+
+func (matcher RequestMatcher) String() string {
+	parts := []string{}
+	if matcher.Method != "" {
+		parts = append(parts, strings.ToUpper(matcher.Method))
+	}
+	if matcher.Path != "" {
+		parts = append(parts, matcher.Path)
+	}
+	if matcher.OperationId != "" {
+		parts = append(parts, "operationId="+matcher.OperationId)
+	}
+	if len(parts) == 0 {
+		return "any request"
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func bodyMatches(expected any, body string) bool {
+	var actual any
+	if err := json.Unmarshal([]byte(body), &actual); err != nil {
+		return expected == body
+	}
+	if text, ok := expected.(string); ok {
+		if err := json.Unmarshal([]byte(text), &expected); err != nil {
+			return text == body
+		}
+	} else {
+		expectedJson, err := json.Marshal(expected)
+		if err != nil || json.Unmarshal(expectedJson, &expected) != nil {
+			return false
+		}
+	}
+
+	return jsonSubset(expected, actual)
+}
+
+func jsonSubset(expected any, actual any) bool {
+	switch typedExpected := expected.(type) {
+	case map[string]any:
+		actualMap, ok := actual.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, value := range typedExpected {
+			actualValue, ok := actualMap[key]
+			if !ok || !jsonSubset(value, actualValue) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		actualSlice, ok := actual.([]any)
+		if !ok || len(actualSlice) != len(typedExpected) {
+			return false
+		}
+		for i, value := range typedExpected {
+			if !jsonSubset(value, actualSlice[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(expected, actual)
+}
+
+func verifyCount(matcher RequestMatcher, count int, matched int) error {
+	if matched == count {
+		return nil
+	}
+	requestsWord := "requests"
+	if count == 1 {
+		requestsWord = "request"
+	}
+
+	return fmt.Errorf("expected %d %s matching %s, got %d", count, requestsWord, matcher, matched)
+}
+
+func harNameValues(values map[string]string) []map[string]string {
+	nameValues := []map[string]string{}
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		nameValues = append(nameValues, map[string]string{"name": name, "value": values[name]})
+	}
+
+	return nameValues
+}
+
+func harLog(requests []RecordedRequest) map[string]any {
+	entries := []any{}
+	for _, request := range requests {
+		queryString := []map[string]string{}
+		for pair := range strings.SplitSeq(request.Query, "&") {
+			if pair == "" {
+				continue
+			}
+			name, value, _ := strings.Cut(pair, "=")
+			name, _ = url.QueryUnescape(name)
+			value, _ = url.QueryUnescape(value)
+			queryString = append(queryString, map[string]string{"name": name, "value": value})
+		}
+		harRequest := map[string]any{
+			"method":      request.Method,
+			"url":         request.Url,
+			"httpVersion": "HTTP/1.1",
+			"cookies":     []any{},
+			"headers":     harNameValues(request.Headers),
+			"queryString": queryString,
+			"headersSize": -1,
+			"bodySize":    len(request.Body),
+		}
+		if request.Body != "" {
+			harRequest["postData"] = map[string]any{"mimeType": request.Headers["content-type"], "text": request.Body}
+		}
+		entries = append(entries, map[string]any{
+			"startedDateTime": request.Timestamp,
+			"time":            0,
+			"request":         harRequest,
+			"response": map[string]any{
+				"status":      request.Status,
+				"statusText":  http.StatusText(request.Status),
+				"httpVersion": "HTTP/1.1",
+				"cookies":     []any{},
+				"headers":     []any{},
+				"content":     map[string]any{"size": 0, "mimeType": ""},
+				"redirectURL": "",
+				"headersSize": -1,
+				"bodySize":    -1,
+			},
+			"cache":   map[string]any{},
+			"timings": map[string]any{"send": 0, "wait": 0, "receive": 0},
+		})
+	}
+
+	return map[string]any{
+		"log": map[string]any{
+			"version": "1.2",
+			"creator": map[string]any{"name": "genmock", "version": ""},
+			"entries": entries,
+		},
+	}
+}
@@ -0,0 +1,244 @@
+package genmock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func journalServer(t *testing.T) *Server {
+	t.Helper()
+
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/exampledbrelations.yaml", GenerateOptions{MaxRecursionDepth: 1, DbRecords: 2, Seed: 1})
+	require.NoError(t, err)
+	server := NewServer(featureFileDataStructure)
+	for _, request := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/customers/1?expand=orders", nil),
+		httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(`{"name":"Rex","tags":["a","b"],"age":3}`)),
+		httptest.NewRequest(http.MethodGet, "/customers/9", nil),
+	} {
+		request.Header.Set("X-Trace", "abc")
+		server.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	return server
+}
+
+func Test_Server_ServeHTTP_RecordsRequestDetails(t *testing.T) {
+	t.Parallel()
+
+	// Act
+	requests := journalServer(t).Requests()
+
+	// Assert
+	require.Len(t, requests, 3)
+	assert.Equal(t, "GET /customers/:customerId", requests[0].Route)
+	assert.Equal(t, "getCustomer", requests[0].OperationId)
+	assert.Equal(t, map[string]string{"customerId": "1"}, requests[0].Params)
+	assert.Equal(t, "abc", requests[0].Headers["x-trace"])
+	assert.Equal(t, "http://example.com/customers/1?expand=orders", requests[0].Url)
+	assert.Equal(t, http.StatusOK, requests[0].Status)
+	assert.False(t, requests[0].Timestamp.IsZero())
+	assert.Empty(t, requests[1].Route)
+	assert.Nil(t, requests[1].Params)
+	assert.Equal(t, http.StatusCreated, requests[1].Status)
+	assert.Equal(t, http.StatusNotFound, requests[2].Status)
+}
+
+func Test_Server_FindRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		matcher       RequestMatcher
+		expectedCount int
+	}{
+		"all":               {expectedCount: 3},
+		"method":            {matcher: RequestMatcher{Method: "post"}, expectedCount: 1},
+		"route path":        {matcher: RequestMatcher{Path: "/customers/{customerId}"}, expectedCount: 2},
+		"request path":      {matcher: RequestMatcher{Path: "/customers/9"}, expectedCount: 1},
+		"operation id":      {matcher: RequestMatcher{OperationId: "getCustomer"}, expectedCount: 2},
+		"query":             {matcher: RequestMatcher{Query: map[string]string{"expand": "orders"}}, expectedCount: 1},
+		"header":            {matcher: RequestMatcher{Headers: map[string]string{"X-Trace": "abc"}}, expectedCount: 3},
+		"partial body":      {matcher: RequestMatcher{Body: map[string]any{"name": "Rex", "age": 3}}, expectedCount: 1},
+		"json string body":  {matcher: RequestMatcher{Body: `{"tags":["a","b"]}`}, expectedCount: 1},
+		"different body":    {matcher: RequestMatcher{Body: map[string]any{"tags": []string{"a"}}}, expectedCount: 0},
+		"combined mismatch": {matcher: RequestMatcher{Method: http.MethodGet, Body: map[string]any{"name": "Rex"}}, expectedCount: 0},
+	}
+
+	server := journalServer(t)
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			count := server.CountRequests(data.matcher)
+
+			// Assert
+			assert.Equal(t, data.expectedCount, count)
+			assert.Len(t, server.FindRequests(data.matcher), data.expectedCount)
+		})
+	}
+}
+
+func Test_Server_VerifyRequests(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server := journalServer(t)
+
+	// Act
+	verified := server.VerifyRequests(RequestMatcher{Method: http.MethodPost, Path: "/customers", Body: map[string]any{"name": "Rex"}}, 1)
+	failed := server.VerifyRequests(RequestMatcher{Method: http.MethodPost, Path: "/customers"}, 2)
+
+	// Assert
+	require.NoError(t, verified)
+	require.EqualError(t, failed, "expected 2 requests matching POST /customers, got 1")
+}
+
+func Test_Server_ExportRequests(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server := journalServer(t)
+
+	// Act
+	jsonExport, jsonErr := server.ExportRequests("json")
+	harExport, harErr := server.ExportRequests("har")
+	_, invalidErr := server.ExportRequests("xml")
+
+	// Assert
+	require.NoError(t, jsonErr)
+	require.NoError(t, harErr)
+	require.EqualError(t, invalidErr, "invalid format 'xml', use json or har")
+	requests := []RecordedRequest{}
+	require.NoError(t, json.Unmarshal(jsonExport, &requests))
+	assert.Len(t, requests, 3)
+	har := struct {
+		Log struct {
+			Version string
+			Entries []struct {
+				Request struct {
+					Method      string
+					Url         string
+					QueryString []map[string]string
+					PostData    *struct {
+						Text string
+					}
+				}
+				Response struct {
+					Status int
+				}
+			}
+		}
+	}{}
+	require.NoError(t, json.Unmarshal(harExport, &har))
+	assert.Equal(t, "1.2", har.Log.Version)
+	require.Len(t, har.Log.Entries, 3)
+	assert.Equal(t, "http://example.com/customers/1?expand=orders", har.Log.Entries[0].Request.Url)
+	assert.Equal(t, []map[string]string{{"name": "expand", "value": "orders"}}, har.Log.Entries[0].Request.QueryString)
+	assert.Nil(t, har.Log.Entries[0].Request.PostData)
+	assert.Equal(t, http.MethodPost, har.Log.Entries[1].Request.Method)
+	assert.JSONEq(t, `{"name":"Rex","tags":["a","b"],"age":3}`, har.Log.Entries[1].Request.PostData.Text)
+	assert.Equal(t, http.StatusCreated, har.Log.Entries[1].Response.Status)
+}
+
+func Test_Server_ServeHTTP_AdminRequestsMatch(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		method        string
+		path          string
+		body          string
+		expectedCode  int
+		expectedBody  string
+		expectedPaths []string
+	}{
+		"filter": {
+			method:        http.MethodGet,
+			path:          "/__admin/requests?operationId=getCustomer&path=/customers/9",
+			expectedCode:  http.StatusOK,
+			expectedPaths: []string{"/customers/9"},
+		},
+		"find": {
+			method:        http.MethodPost,
+			path:          "/__admin/requests/find",
+			body:          `{"headers": {"X-Trace": "abc"}, "query": {"expand": "orders"}}`,
+			expectedCode:  http.StatusOK,
+			expectedPaths: []string{"/customers/1"},
+		},
+		"count": {
+			method:       http.MethodPost,
+			path:         "/__admin/requests/count",
+			body:         `{"path": "/customers/{customerId}"}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"count":2}`,
+		},
+		"verify": {
+			method:       http.MethodPost,
+			path:         "/__admin/requests/verify",
+			body:         `{"method": "POST", "path": "/customers", "body": {"name": "Rex"}, "count": 1}`,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"count":1}`,
+		},
+		"verify count": {
+			method:       http.MethodPost,
+			path:         "/__admin/requests/verify",
+			body:         `{"method": "POST", "path": "/customers", "count": 2}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"message":"expected 2 requests matching POST /customers, got 1","count":1}`,
+		},
+		"verify at least one": {
+			method:       http.MethodPost,
+			path:         "/__admin/requests/verify",
+			body:         `{"operationId": "getSupplier"}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedBody: `{"message":"expected at least one request matching operationId=getSupplier, got 0","count":0}`,
+		},
+		"invalid matcher": {
+			method:       http.MethodPost,
+			path:         "/__admin/requests/count",
+			body:         `[]`,
+			expectedCode: http.StatusBadRequest,
+		},
+		"invalid format": {
+			method:       http.MethodGet,
+			path:         "/__admin/requests/export?format=xml",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"message":"invalid format 'xml', use json or har"}`,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := journalServer(t)
+			recorder := httptest.NewRecorder()
+
+			// Act
+			server.ServeHTTP(recorder, httptest.NewRequest(data.method, data.path, strings.NewReader(data.body)))
+
+			// Assert
+			assert.Equal(t, data.expectedCode, recorder.Code)
+			if data.expectedBody != "" {
+				assert.JSONEq(t, data.expectedBody, recorder.Body.String())
+			}
+			if data.expectedPaths != nil {
+				requests := []RecordedRequest{}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &requests))
+				paths := []string{}
+				for _, request := range requests {
+					paths = append(paths, request.Path)
+				}
+				assert.Equal(t, data.expectedPaths, paths)
+			}
+		})
+	}
+}
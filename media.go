@@ -0,0 +1,557 @@
+package genmock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v2high "github.com/pb33f/libopenapi/datamodel/high/v2"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"github.com/pb33f/libopenapi/orderedmap"
+	orderedmapv2 "github.com/pb33f/ordered-map/v2"
+	"go.yaml.in/yaml/v4"
+)
+
+const (
+	multipartBoundary = "genmock-boundary"
+	binaryLength      = 16
+)
+
+type ResponseContent struct {
+	MediaType   string `json:"mediaType"`
+	ContentType string `json:"contentType"`
+	Body        string `json:"body,omitempty"`
+	Binary      bool   `json:"binary,omitempty"`
+	Json        bool   `json:"json,omitempty"`
+}
+
+type schemaResolver func(*base.SchemaProxy) *base.Schema
+
+func mediaTypeName(mediaType string) string {
+	name, _, err := mime.ParseMediaType(mediaType)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(mediaType))
+	}
+
+	return name
+}
+
+func isJsonMediaType(mediaType string) bool {
+	name := mediaTypeName(mediaType)
+
+	return name == "application/json" || strings.HasSuffix(name, "+json")
+}
+
+func jsonMediaTypeV3(content *orderedmap.Map[string, *v3high.MediaType]) *v3high.MediaType {
+	newest := content.Newest()
+	if newest == nil {
+		return nil
+	}
+	if isJsonMediaType(newest.Key) {
+		return newest.Value
+	}
+	for mediaType, mediaTypeContent := range content.FromOldest() {
+		if isJsonMediaType(mediaType) {
+			return mediaTypeContent
+		}
+	}
+
+	return newest.Value
+}
+
+func sortContent(content []ResponseContent) []ResponseContent {
+	if !slices.ContainsFunc(content, func(entry ResponseContent) bool { return !entry.Json }) {
+		return nil
+	}
+	sort.SliceStable(content, func(i, j int) bool {
+		return content[i].Json && !content[j].Json
+	})
+
+	return content
+}
+
+func responseContentV3(response *v3high.Response, definitions *orderedmapv2.OrderedMap[string, *base.SchemaProxy], opts GenerateOptions) []ResponseContent {
+	if response == nil || orderedmap.Len(response.Content) < 1 {
+		return nil
+	}
+	resolve := func(schema *base.SchemaProxy) *base.Schema {
+		return resolveSchemaV3(schema, definitions)
+	}
+	content := []ResponseContent{}
+	for mediaType, mediaTypeContent := range response.Content.FromOldest() {
+		if isJsonMediaType(mediaType) {
+			content = append(content, ResponseContent{MediaType: mediaTypeName(mediaType), ContentType: mediaType, Json: true})
+			continue
+		}
+		var body any
+		exampleBody, _, hasExample := mediaTypeExampleV3(mediaTypeContent, opts.ExampleName)
+		if opts.GenExamples && hasExample {
+			body = exampleBody
+		} else if mediaTypeContent.Schema != nil {
+			schema := resolve(mediaTypeContent.Schema)
+			if schemaType := schemaTypeV3(schema, opts); schemaType != "object" && schemaType != "array" && schemaType != "" {
+				body, _ = propertyValueV3(schema, definitions, 0, opts)
+			} else {
+				body = schemaToPropertyMapV3(mediaTypeContent.Schema, definitions, nil, 0, opts)
+			}
+		}
+		content = append(content, mediaContent(mediaType, mediaTypeContent.Schema, body, resolve, opts))
+	}
+
+	return sortContent(content)
+}
+
+func responseContentV2(response *v2high.Response, produces []string, definitions *orderedmap.Map[string, *base.SchemaProxy], opts GenerateOptions) []ResponseContent {
+	if response == nil || len(produces) < 1 {
+		return nil
+	}
+	resolve := func(schema *base.SchemaProxy) *base.Schema {
+		return schema.Schema()
+	}
+	content := []ResponseContent{}
+	for _, mediaType := range produces {
+		if isJsonMediaType(mediaType) {
+			content = append(content, ResponseContent{MediaType: mediaTypeName(mediaType), ContentType: mediaType, Json: true})
+			continue
+		}
+		var body any
+		var example *yaml.Node
+		if response.Examples != nil {
+			example = response.Examples.Values.GetOrZero(mediaType)
+		}
+		if opts.GenExamples && example != nil {
+			body = nodeValue(example)
+		} else if response.Schema != nil {
+			schema := resolve(response.Schema)
+			if len(schema.Type) > 0 && schema.Type[0] != "object" && schema.Type[0] != "array" {
+				body, _ = propertyValueV2(schema, definitions, 0, opts)
+			} else {
+				body = schemaToPropertyMapV2(response.Schema, definitions, nil, 0, opts)
+			}
+		}
+		content = append(content, mediaContent(mediaType, response.Schema, body, resolve, opts))
+	}
+
+	return sortContent(content)
+}
+
+func mediaContent(mediaType string, schemaProxy *base.SchemaProxy, body any, resolve schemaResolver, opts GenerateOptions) ResponseContent {
+	name := mediaTypeName(mediaType)
+	content := ResponseContent{MediaType: name, ContentType: mediaType}
+	var schema *base.Schema
+	if schemaProxy != nil {
+		schema = resolve(schemaProxy)
+	}
+	switch {
+	case name == "application/xml" || name == "text/xml" || strings.HasSuffix(name, "+xml"):
+		if text, ok := body.(string); ok {
+			content.Body = text
+			break
+		}
+		content.Body = xmlContent(schemaProxy, body, resolve)
+	case name == "text/csv":
+		content.Body = csvContent(schema, body, resolve)
+	case name == "application/x-www-form-urlencoded":
+		content.Body = formContent(body)
+	case strings.HasPrefix(name, "multipart/"):
+		content.Body = multipartContent(schema, body, resolve)
+		content.ContentType = fmt.Sprintf("%s; boundary=%s", name, multipartBoundary)
+	case name == "application/yaml" || name == "application/x-yaml" || name == "text/yaml":
+		if text, ok := body.(string); ok {
+			content.Body = text
+			break
+		}
+		yamlContent, _ := yaml.Marshal(body)
+		content.Body = string(yamlContent)
+	case strings.HasPrefix(name, "text/") && !isBinarySchema(schema):
+		content.Body = textValue(body)
+	default:
+		content.Binary = true
+		content.Body = base64.StdEncoding.EncodeToString(binaryContent(schema, body, opts))
+	}
+
+	return content
+}
+
+func isBinarySchema(schema *base.Schema) bool {
+	return schema != nil && (schema.Format == "binary" || schema.ContentEncoding == "binary" || schema.ContentMediaType != "")
+}
+
+func textValue(value any) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return typedValue
+	case map[string]any, []any:
+		valueJson, _ := json.Marshal(typedValue)
+		return string(valueJson)
+	default:
+		return fmt.Sprint(typedValue)
+	}
+}
+
+func binaryContent(schema *base.Schema, body any, opts GenerateOptions) []byte {
+	if text, ok := body.(string); ok && text != "" {
+		return []byte(text)
+	}
+	if !opts.GenExamples {
+		return []byte{}
+	}
+	length := binaryLength
+	if schema != nil && schema.MinLength != nil && int(*schema.MinLength) > length {
+		length = int(*schema.MinLength)
+	}
+	if schema != nil && schema.MaxLength != nil && int(*schema.MaxLength) < length {
+		length = int(*schema.MaxLength)
+	}
+	content := make([]byte, length)
+	for i := range content {
+		content[i] = byte(opts.fake().IntN(256))
+	}
+
+	return content
+}
+
+func objectKeys(schema *base.Schema, object map[string]any, resolve schemaResolver) ([]string, map[string]*base.Schema) {
+	keys := []string{}
+	schemas := map[string]*base.Schema{}
+	var collect func(schema *base.Schema)
+	collect = func(schema *base.Schema) {
+		if schema == nil {
+			return
+		}
+		for _, allOfSchema := range schema.AllOf {
+			collect(resolve(allOfSchema))
+		}
+		for name, propertySchema := range schema.Properties.FromOldest() {
+			if _, ok := schemas[name]; ok {
+				continue
+			}
+			schemas[name] = resolve(propertySchema)
+			if _, ok := object[name]; ok || object == nil {
+				keys = append(keys, name)
+			}
+		}
+	}
+	collect(schema)
+	extraKeys := []string{}
+	for key := range object {
+		if _, ok := schemas[key]; !ok {
+			extraKeys = append(extraKeys, key)
+		}
+	}
+	slices.Sort(extraKeys)
+
+	return append(keys, extraKeys...), schemas
+}
+
+func itemSchema(schema *base.Schema, resolve schemaResolver) (*base.SchemaProxy, *base.Schema) {
+	if schema == nil || schema.Items == nil || !schema.Items.IsA() {
+		return nil, nil
+	}
+
+	return schema.Items.A, resolve(schema.Items.A)
+}
+
+func referenceName(schemaProxy *base.SchemaProxy) string {
+	if schemaProxy == nil || !schemaProxy.IsReference() {
+		return ""
+	}
+	reference := schemaProxy.GetReference()
+
+	return reference[strings.LastIndex(reference, "/")+1:]
+}
+
+func xmlName(schema *base.Schema, fallback string) string {
+	if schema == nil || schema.XML == nil {
+		return fallback
+	}
+	name := fallback
+	if schema.XML.Name != "" {
+		name = schema.XML.Name
+	}
+	if schema.XML.Prefix != "" {
+		name = fmt.Sprintf("%s:%s", schema.XML.Prefix, name)
+	}
+
+	return name
+}
+
+func xmlNamespace(schema *base.Schema) string {
+	if schema == nil || schema.XML == nil || schema.XML.Namespace == "" {
+		return ""
+	}
+	if schema.XML.Prefix != "" {
+		return fmt.Sprintf(` xmlns:%s="%s"`, schema.XML.Prefix, xmlEscape(schema.XML.Namespace))
+	}
+
+	return fmt.Sprintf(` xmlns="%s"`, xmlEscape(schema.XML.Namespace))
+}
+
+func isXmlAttribute(schema *base.Schema) bool {
+	return schema != nil && schema.XML != nil && (schema.XML.Attribute || schema.XML.NodeType == "attribute")
+}
+
+func xmlEscape(value string) string {
+	var buffer bytes.Buffer
+	_ = xml.EscapeText(&buffer, []byte(value))
+
+	return buffer.String()
+}
+
+func xmlContent(schemaProxy *base.SchemaProxy, body any, resolve schemaResolver) string {
+	var schema *base.Schema
+	if schemaProxy != nil {
+		schema = resolve(schemaProxy)
+	}
+	name := referenceName(schemaProxy)
+	if name == "" {
+		name = "root"
+	}
+	var buffer strings.Builder
+	buffer.WriteString(xml.Header)
+	if items, ok := body.([]any); ok {
+		itemProxy, itemSchema := itemSchema(schema, resolve)
+		itemName := referenceName(itemProxy)
+		if itemName == "" {
+			itemName = "item"
+		}
+		rootName := xmlName(schema, name)
+		fmt.Fprintf(&buffer, "<%s%s>", rootName, xmlNamespace(schema))
+		for _, item := range items {
+			writeXmlElement(&buffer, xmlName(itemSchema, itemName), itemSchema, item, resolve)
+		}
+		fmt.Fprintf(&buffer, "</%s>", rootName)
+		return buffer.String()
+	}
+	writeXmlElement(&buffer, xmlName(schema, name), schema, body, resolve)
+
+	return buffer.String()
+}
+
+func writeXmlElement(buffer *strings.Builder, name string, schema *base.Schema, value any, resolve schemaResolver) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		fmt.Fprintf(buffer, "<%s%s>%s</%s>", name, xmlNamespace(schema), xmlEscape(textValue(value)), name)
+		return
+	}
+	keys, schemas := objectKeys(schema, object, resolve)
+	attributes := xmlNamespace(schema)
+	var children strings.Builder
+	for _, key := range keys {
+		propertySchema := schemas[key]
+		propertyName := xmlName(propertySchema, key)
+		if isXmlAttribute(propertySchema) {
+			attributes = fmt.Sprintf(`%s %s="%s"`, attributes, propertyName, xmlEscape(textValue(object[key])))
+			continue
+		}
+		items, isArray := object[key].([]any)
+		if !isArray {
+			writeXmlElement(&children, propertyName, propertySchema, object[key], resolve)
+			continue
+		}
+		_, itemSchema := itemSchema(propertySchema, resolve)
+		itemName := xmlName(itemSchema, propertyName)
+		wrapped := propertySchema != nil && propertySchema.XML != nil && propertySchema.XML.Wrapped
+		if wrapped {
+			fmt.Fprintf(&children, "<%s%s>", propertyName, xmlNamespace(propertySchema))
+		}
+		for _, item := range items {
+			writeXmlElement(&children, itemName, itemSchema, item, resolve)
+		}
+		if wrapped {
+			fmt.Fprintf(&children, "</%s>", propertyName)
+		}
+	}
+	fmt.Fprintf(buffer, "<%s%s>%s</%s>", name, attributes, children.String(), name)
+}
+
+func csvContent(schema *base.Schema, body any, resolve schemaResolver) string {
+	if text, ok := body.(string); ok {
+		return text
+	}
+	rows, ok := body.([]any)
+	if !ok {
+		rows = []any{body}
+	}
+	_, rowSchema := itemSchema(schema, resolve)
+	if _, isArray := body.([]any); !isArray {
+		rowSchema = schema
+	}
+	var columns []string
+	for _, row := range rows {
+		object, _ := row.(map[string]any)
+		keys, _ := objectKeys(rowSchema, object, resolve)
+		for _, key := range keys {
+			if !slices.Contains(columns, key) {
+				columns = append(columns, key)
+			}
+		}
+	}
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	if len(columns) == 0 {
+		for _, row := range rows {
+			_ = writer.Write([]string{textValue(row)})
+		}
+		writer.Flush()
+		return buffer.String()
+	}
+	_ = writer.Write(columns)
+	for _, row := range rows {
+		object, _ := row.(map[string]any)
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = textValue(object[column])
+		}
+		_ = writer.Write(record)
+	}
+	writer.Flush()
+
+	return buffer.String()
+}
+
+func formContent(body any) string {
+	object, ok := body.(map[string]any)
+	if !ok {
+		return textValue(body)
+	}
+	values := url.Values{}
+	for key, value := range object {
+		if items, isArray := value.([]any); isArray {
+			for _, item := range items {
+				values.Add(key, textValue(item))
+			}
+			continue
+		}
+		values.Set(key, textValue(value))
+	}
+
+	return values.Encode()
+}
+
+func multipartContent(schema *base.Schema, body any, resolve schemaResolver) string {
+	object, ok := body.(map[string]any)
+	if !ok {
+		object = map[string]any{"file": body}
+	}
+	var buffer bytes.Buffer
+	writer := multipart.NewWriter(&buffer)
+	_ = writer.SetBoundary(multipartBoundary)
+	keys, schemas := objectKeys(schema, object, resolve)
+	for _, key := range keys {
+		values, isArray := object[key].([]any)
+		partSchema := schemas[key]
+		if isArray {
+			_, partSchema = itemSchema(partSchema, resolve)
+		} else {
+			values = []any{object[key]}
+		}
+		for _, value := range values {
+			header := textproto.MIMEHeader{}
+			disposition := fmt.Sprintf(`form-data; name="%s"`, key)
+			contentType := "text/plain"
+			if isBinarySchema(partSchema) {
+				disposition = fmt.Sprintf(`%s; filename="%s"`, disposition, key)
+				contentType = "application/octet-stream"
+			} else if _, isObject := value.(map[string]any); isObject {
+				contentType = "application/json"
+			}
+			header.Set("Content-Disposition", disposition)
+			header.Set("Content-Type", contentType)
+			part, err := writer.CreatePart(header)
+			if err != nil {
+				continue
+			}
+			_, _ = part.Write([]byte(textValue(value)))
+		}
+	}
+	_ = writer.Close()
+
+	return buffer.String()
+}
+
+func acceptPriority(accept string, mediaType string) (float64, int, int, bool) {
+	quality, specificity, order, matched := 0.0, -1, -1, false
+	for acceptIndex, acceptRange := range strings.Split(accept, ",") {
+		rangeName, params, err := mime.ParseMediaType(strings.TrimSpace(acceptRange))
+		if err != nil {
+			continue
+		}
+		rangeQuality := 1.0
+		if q, ok := params["q"]; ok {
+			rangeQuality, err = strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+		}
+		rangeType, rangeSubtype, _ := strings.Cut(rangeName, "/")
+		mediaTypeType, mediaTypeSubtype, _ := strings.Cut(mediaType, "/")
+		rangeSpecificity := 0
+		if rangeType == mediaTypeType {
+			rangeSpecificity += 4
+		} else if rangeType != "*" {
+			continue
+		}
+		if rangeSubtype == mediaTypeSubtype {
+			rangeSpecificity += 2
+		} else if rangeSubtype != "*" {
+			continue
+		}
+		if !matched || rangeSpecificity > specificity || (rangeSpecificity == specificity && rangeQuality > quality) {
+			quality, specificity, order, matched = rangeQuality, rangeSpecificity, acceptIndex, true
+		}
+	}
+
+	return quality, specificity, order, matched && quality > 0
+}
+
+func negotiateContent(accept string, content []ResponseContent) (ResponseContent, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return content[0], true
+	}
+	bestIndex := -1
+	var bestQuality float64
+	var bestSpecificity, bestOrder int
+	for i, entry := range content {
+		quality, specificity, order, ok := acceptPriority(accept, entry.MediaType)
+		if !ok {
+			continue
+		}
+		if bestIndex == -1 || quality > bestQuality || (quality == bestQuality && (specificity > bestSpecificity || (specificity == bestSpecificity && order < bestOrder))) {
+			bestIndex, bestQuality, bestSpecificity, bestOrder = i, quality, specificity, order
+		}
+	}
+	if bestIndex == -1 {
+		return ResponseContent{}, false
+	}
+
+	return content[bestIndex], true
+}
+
+func writeContent(w http.ResponseWriter, statusCode int, content ResponseContent) {
+	body := []byte(content.Body)
+	contentType := content.ContentType
+	if content.Binary {
+		body, _ = base64.StdEncoding.DecodeString(content.Body)
+	} else if !strings.Contains(strings.ToLower(contentType), "charset=") {
+		contentType = fmt.Sprintf("%s; charset=utf-8", contentType)
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+	_, _ = w.Write(body)
+}
@@ -0,0 +1,167 @@
+package genmock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func Test_SpecToRequestStructureMap_SerializesMediaTypes(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		specFilename    string
+		path            string
+		expectedContent []ResponseContent
+	}{
+		"v3 xml": {
+			specFilename: "./testdata/examplemedia.yaml",
+			path:         "/pets/:petId",
+			expectedContent: []ResponseContent{
+				{MediaType: "application/json", ContentType: "application/json", Json: true},
+				{MediaType: "application/xml", ContentType: "application/xml", Body: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<pet id="1"><name>Rex &amp; Co</name><tags><tag>dog</tag><tag>good</tag></tags></pet>`},
+			},
+		},
+		"v3 multipart and text": {
+			specFilename: "./testdata/examplemedia.yaml",
+			path:         "/pets/:petId/profile",
+			expectedContent: []ResponseContent{
+				{
+					MediaType:   "multipart/form-data",
+					ContentType: "multipart/form-data; boundary=genmock-boundary",
+					Body: "--genmock-boundary\r\nContent-Disposition: form-data; name=\"name\"\r\nContent-Type: text/plain\r\n\r\nRex\r\n" +
+						"--genmock-boundary\r\nContent-Disposition: form-data; name=\"photo\"; filename=\"photo\"\r\nContent-Type: application/octet-stream\r\n\r\nPNG\r\n" +
+						"--genmock-boundary--\r\n",
+				},
+				{MediaType: "text/plain", ContentType: "text/plain", Body: "Rex is a good dog"},
+			},
+		},
+		"v2 xml": {
+			specFilename: "./testdata/examplemediav2.yaml",
+			path:         "/pets/:petId",
+			expectedContent: []ResponseContent{
+				{MediaType: "application/json", ContentType: "application/json", Json: true},
+				{MediaType: "application/xml", ContentType: "application/xml", Body: `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<pet id="1"><name>Rex</name><tags><tag>dog</tag></tags></pet>`},
+			},
+		},
+		"json only": {
+			specFilename: "./testdata/examplev3.yaml",
+			path:         "/products",
+		},
+		"empty content": {
+			specFilename: "./testdata/examplemediaempty.yaml",
+			path:         "/pets",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			resultMap, err := SpecToRequestStructureMap(data.specFilename, GenerateOptions{MaxRecursionDepth: 1, GenExamples: true})
+
+			// Assert
+			require.NoError(t, err)
+			require.NotEmpty(t, resultMap["get"][data.path])
+			assert.Equal(t, data.expectedContent, resultMap["get"][data.path][0].ResponseContent)
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_NegotiatesContent(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		path                string
+		accept              string
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+	}{
+		"default json": {
+			path:                "/pets/1",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json; charset=utf-8",
+		},
+		"xml": {
+			path:                "/pets/1",
+			accept:              "application/xml",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/xml; charset=utf-8",
+			expectedBody:        `<?xml version="1.0" encoding="UTF-8"?>` + "\n" + `<pet id="1"><name>Rex &amp; Co</name><tags><tag>dog</tag><tag>good</tag></tags></pet>`,
+		},
+		"quality": {
+			path:                "/pets",
+			accept:              "application/json;q=0.5, text/csv",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody:        "id,name\n1,Rex\n",
+		},
+		"wildcard": {
+			path:                "/pets/1/profile",
+			accept:              "text/*",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/plain; charset=utf-8",
+			expectedBody:        "Rex is a good dog",
+		},
+		"binary": {
+			path:                "/pets/1/photo",
+			accept:              "image/*",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "image/png",
+		},
+		"not acceptable": {
+			path:                "/pets/1",
+			accept:              "text/html",
+			expectedCode:        http.StatusNotAcceptable,
+			expectedContentType: "application/json; charset=utf-8",
+			expectedBody:        `{"accepted":["application/json","application/xml"],"message":"not acceptable"}` + "\n",
+		},
+	}
+
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplemedia.yaml", GenerateOptions{MaxRecursionDepth: 1, GenExamples: true})
+	require.NoError(t, err)
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := NewServer(featureFileDataStructure)
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, data.path, nil)
+			request.Header.Set("Accept", data.accept)
+
+			// Act
+			server.ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, data.expectedCode, recorder.Code)
+			assert.Equal(t, data.expectedContentType, recorder.Header().Get("Content-Type"))
+			if data.expectedBody != "" {
+				assert.Equal(t, data.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func Test_GenerateServerFile_SendsContent(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplemedia.yaml", GenerateOptions{MaxRecursionDepth: 1})
+	require.NoError(t, err)
+
+	// Act
+	result, err := GenerateServerFile("http", 5000, "db.json", featureFileDataStructure)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, result, `if (statusCode === 200 && sendContent(req, res, statusCode, [{"mediaType":"image/png","contentType":"image/png","binary":true}])) {
+		return;
+	}`)
+}
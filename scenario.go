@@ -0,0 +1,239 @@
+package genmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+
+	"go.yaml.in/yaml/v4"
+)
+
+type Scenario struct {
+	Name         string                   `yaml:"name"`
+	InitialState string                   `yaml:"initialState"`
+	States       map[string]ScenarioState `yaml:"states"`
+}
+
+type ScenarioState struct {
+	Responses   map[string]ScenarioResponse `yaml:"responses"`
+	Transitions []ScenarioTransition        `yaml:"transitions"`
+}
+
+type ScenarioResponse struct {
+	Status  int               `yaml:"status" json:"status,omitempty"`
+	Headers map[string]string `yaml:"headers" json:"headers,omitempty"`
+	Body    any               `yaml:"body" json:"body,omitempty"`
+}
+
+type ScenarioTransition struct {
+	On    string `yaml:"on"`
+	After int    `yaml:"after"`
+	To    string `yaml:"to"`
+}
+
+type RouteScenario struct {
+	Name         string                      `json:"name"`
+	InitialState string                      `json:"initialState"`
+	States       []string                    `json:"states"`
+	Responses    map[string]ScenarioResponse `json:"responses,omitempty"`
+	Transitions  []StateTransition           `json:"transitions,omitempty"`
+}
+
+type StateTransition struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	After int    `json:"after,omitempty"`
+}
+
+func LoadScenarios(filename string) ([]Scenario, error) {
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	scenarioFile := struct {
+		Scenarios []Scenario `yaml:"scenarios"`
+	}{}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&scenarioFile); err != nil {
+		return nil, fmt.Errorf("invalid scenario file '%s': %w", filename, err)
+	}
+
+	return scenarioFile.Scenarios, nil
+}
+
+func attachScenarios(featureFileDataStructure map[string]map[string][]RequestStructure, scenarios []Scenario) error {
+	operationIds := map[string]bool{}
+	for _, calls := range featureFileDataStructure {
+		for _, requests := range calls {
+			for _, request := range requests {
+				operationIds[request.OperationId] = request.OperationId != ""
+			}
+		}
+	}
+
+	operationScenarios := map[string][]RouteScenario{}
+	names := map[string]bool{}
+	for _, scenario := range scenarios {
+		if scenario.Name == "" || names[scenario.Name] {
+			return fmt.Errorf("invalid scenario name '%s', every scenario needs a unique name", scenario.Name)
+		}
+		names[scenario.Name] = true
+		if _, ok := scenario.States[scenario.InitialState]; !ok {
+			return fmt.Errorf("scenario '%s' has an unknown initial state '%s'", scenario.Name, scenario.InitialState)
+		}
+		states := slices.Sorted(maps.Keys(scenario.States))
+		routeScenarios := map[string]*RouteScenario{}
+		routeScenario := func(operationId string) *RouteScenario {
+			if _, ok := routeScenarios[operationId]; !ok {
+				routeScenarios[operationId] = &RouteScenario{Name: scenario.Name, InitialState: scenario.InitialState, States: states}
+			}
+			return routeScenarios[operationId]
+		}
+		for _, stateName := range states {
+			state := scenario.States[stateName]
+			for _, operationId := range slices.Sorted(maps.Keys(state.Responses)) {
+				response := state.Responses[operationId]
+				if !operationIds[operationId] {
+					return fmt.Errorf("scenario '%s' state '%s' responds to unknown operationId '%s'", scenario.Name, stateName, operationId)
+				}
+				if response.Status != 0 && (response.Status < 100 || response.Status > 599) {
+					return fmt.Errorf("scenario '%s' state '%s' has an invalid status %d for '%s'", scenario.Name, stateName, response.Status, operationId)
+				}
+				if response.Body != nil {
+					bodyJson, err := json.Marshal(response.Body)
+					if err != nil {
+						return fmt.Errorf("scenario '%s' state '%s' has an invalid body for '%s': %w", scenario.Name, stateName, operationId, err)
+					}
+					_ = json.Unmarshal(bodyJson, &response.Body)
+				}
+				target := routeScenario(operationId)
+				if target.Responses == nil {
+					target.Responses = map[string]ScenarioResponse{}
+				}
+				target.Responses[stateName] = response
+			}
+			for _, transition := range state.Transitions {
+				if !operationIds[transition.On] {
+					return fmt.Errorf("scenario '%s' state '%s' has a transition on unknown operationId '%s'", scenario.Name, stateName, transition.On)
+				}
+				if _, ok := scenario.States[transition.To]; !ok {
+					return fmt.Errorf("scenario '%s' state '%s' has a transition to unknown state '%s'", scenario.Name, stateName, transition.To)
+				}
+				if transition.After < 0 {
+					return fmt.Errorf("scenario '%s' state '%s' has an invalid after %d, use a positive number of calls", scenario.Name, stateName, transition.After)
+				}
+				target := routeScenario(transition.On)
+				target.Transitions = append(target.Transitions, StateTransition{From: stateName, To: transition.To, After: transition.After})
+			}
+		}
+		for _, operationId := range slices.Sorted(maps.Keys(routeScenarios)) {
+			operationScenarios[operationId] = append(operationScenarios[operationId], *routeScenarios[operationId])
+		}
+	}
+
+	for _, calls := range featureFileDataStructure {
+		for _, requests := range calls {
+			for i := range requests {
+				requests[i].Scenarios = operationScenarios[requests[i].OperationId]
+			}
+		}
+	}
+
+	return nil
+}
+
+func operationScenarios(featureFileDataStructure map[string]map[string][]RequestStructure) map[string][]RouteScenario {
+	scenarios := map[string][]RouteScenario{}
+	for _, calls := range featureFileDataStructure {
+		for _, requests := range calls {
+			for _, request := range requests {
+				if len(request.Scenarios) > 0 {
+					scenarios[request.OperationId] = request.Scenarios
+				}
+			}
+		}
+	}
+
+	return scenarios
+}
+
+func (s *Server) ScenarioStates() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return maps.Clone(s.scenarioStates)
+}
+
+func (s *Server) SetScenarioStates(states map[string]string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, state := range states {
+		knownStates, ok := s.scenarios[name]
+		if !ok {
+			return fmt.Errorf("unknown scenario '%s'", name)
+		}
+		if !slices.Contains(knownStates, state) {
+			return fmt.Errorf("unknown state '%s' in scenario '%s'", state, name)
+		}
+	}
+	for name, state := range states {
+		s.scenarioStates[name] = state
+		delete(s.scenarioCalls, name)
+	}
+
+	return nil
+}
+
+func (s *Server) resetScenarios() {
+	s.scenarioStates = map[string]string{}
+	s.scenarioCalls = map[string]map[string]int{}
+	for _, route := range s.routes {
+		for _, scenario := range route.request.Scenarios {
+			s.scenarios[scenario.Name] = scenario.States
+			s.scenarioStates[scenario.Name] = scenario.InitialState
+		}
+	}
+}
+
+func (s *Server) takeScenarioResponse(request RequestStructure) (ScenarioResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	response := ScenarioResponse{}
+	found := false
+	for _, scenario := range request.Scenarios {
+		state := s.scenarioStates[scenario.Name]
+		if stateResponse, ok := scenario.Responses[state]; ok && !found {
+			response = stateResponse
+			found = true
+		}
+		index := slices.IndexFunc(scenario.Transitions, func(transition StateTransition) bool {
+			return transition.From == state
+		})
+		if index < 0 {
+			continue
+		}
+		if s.scenarioCalls[scenario.Name] == nil {
+			s.scenarioCalls[scenario.Name] = map[string]int{}
+		}
+		s.scenarioCalls[scenario.Name][request.OperationId]++
+		if s.scenarioCalls[scenario.Name][request.OperationId] >= max(scenario.Transitions[index].After, 1) {
+			s.scenarioStates[scenario.Name] = scenario.Transitions[index].To
+			delete(s.scenarioCalls, scenario.Name)
+		}
+	}
+
+	return response, found
+}
+
+func writeScenarioResponse(w http.ResponseWriter, matchedRoute *route, response ScenarioResponse) {
+	override := routeOverride{Status: response.Status, Headers: response.Headers}
+	if response.Body != nil {
+		override.Body, _ = json.Marshal(response.Body)
+	}
+	writeOverride(w, matchedRoute, override)
+}
@@ -0,0 +1,331 @@
+package genmock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func scenarioServer(t *testing.T) *Server {
+	t.Helper()
+
+	scenarios, err := LoadScenarios("./testdata/examplescenarios-flows.yaml")
+	require.NoError(t, err)
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplescenarios.yaml", GenerateOptions{MaxRecursionDepth: 1, Scenarios: scenarios})
+	require.NoError(t, err)
+
+	return NewServer(featureFileDataStructure)
+}
+
+func Test_LoadScenarios(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		content       string
+		expectedNames []string
+		expectedErr   string
+	}{
+		"scenarios": {
+			content:       "scenarios:\n  - name: job\n    initialState: pending\n  - name: session\n    initialState: loggedOut\n",
+			expectedNames: []string{"job", "session"},
+		},
+		"empty": {
+			content: "scenarios: []\n",
+		},
+		"unknown field": {
+			content:     "scenarios:\n  - name: job\n    initial: pending\n",
+			expectedErr: "field initial not found",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			filename := filepath.Join(t.TempDir(), "scenarios.yaml")
+			require.NoError(t, os.WriteFile(filename, []byte(data.content), 0o600))
+
+			// Act
+			scenarios, err := LoadScenarios(filename)
+
+			// Assert
+			if data.expectedErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), data.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			names := []string{}
+			for _, scenario := range scenarios {
+				names = append(names, scenario.Name)
+			}
+			assert.Equal(t, append([]string{}, data.expectedNames...), names)
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_AttachesScenarios(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	scenarios, err := LoadScenarios("./testdata/examplescenarios-flows.yaml")
+	require.NoError(t, err)
+
+	// Act
+	resultMap, err := SpecToRequestStructureMap("./testdata/examplescenarios.yaml", GenerateOptions{MaxRecursionDepth: 1, Scenarios: scenarios})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []RouteScenario{{
+		Name:         "job",
+		InitialState: "pending",
+		States:       []string{"done", "pending"},
+		Responses: map[string]ScenarioResponse{
+			"done":    {Headers: map[string]string{"X-Job-Status": "done"}, Body: map[string]any{"id": float64(1), "status": "done"}},
+			"pending": {Body: map[string]any{"id": float64(1), "status": "pending"}},
+		},
+		Transitions: []StateTransition{{From: "pending", To: "done", After: 2}},
+	}}, resultMap["get"]["/jobs/:jobId"][0].Scenarios)
+	assert.Equal(t, []RouteScenario{{
+		Name:         "session",
+		InitialState: "loggedOut",
+		States:       []string{"loggedIn", "loggedOut"},
+		Transitions:  []StateTransition{{From: "loggedOut", To: "loggedIn"}},
+	}}, resultMap["post"]["/login"][0].Scenarios)
+	assert.Nil(t, resultMap["get"]["/health"][0].Scenarios)
+}
+
+func Test_SpecToRequestStructureMap_InvalidScenarios(t *testing.T) {
+	t.Parallel()
+
+	state := func(responses map[string]ScenarioResponse, transitions ...ScenarioTransition) ScenarioState {
+		return ScenarioState{Responses: responses, Transitions: transitions}
+	}
+
+	tests := map[string]struct {
+		scenarios   []Scenario
+		expectedErr string
+	}{
+		"no name": {
+			scenarios:   []Scenario{{InitialState: "start", States: map[string]ScenarioState{"start": {}}}},
+			expectedErr: "invalid scenario name '', every scenario needs a unique name",
+		},
+		"duplicate name": {
+			scenarios: []Scenario{
+				{Name: "job", InitialState: "start", States: map[string]ScenarioState{"start": {}}},
+				{Name: "job", InitialState: "start", States: map[string]ScenarioState{"start": {}}},
+			},
+			expectedErr: "invalid scenario name 'job', every scenario needs a unique name",
+		},
+		"unknown initial state": {
+			scenarios:   []Scenario{{Name: "job", InitialState: "pending", States: map[string]ScenarioState{"start": {}}}},
+			expectedErr: "scenario 'job' has an unknown initial state 'pending'",
+		},
+		"unknown response operation": {
+			scenarios:   []Scenario{{Name: "job", InitialState: "start", States: map[string]ScenarioState{"start": state(map[string]ScenarioResponse{"getJobs": {}})}}},
+			expectedErr: "scenario 'job' state 'start' responds to unknown operationId 'getJobs'",
+		},
+		"invalid status": {
+			scenarios:   []Scenario{{Name: "job", InitialState: "start", States: map[string]ScenarioState{"start": state(map[string]ScenarioResponse{"getJob": {Status: 42}})}}},
+			expectedErr: "scenario 'job' state 'start' has an invalid status 42 for 'getJob'",
+		},
+		"unknown transition operation": {
+			scenarios:   []Scenario{{Name: "job", InitialState: "start", States: map[string]ScenarioState{"start": state(nil, ScenarioTransition{On: "cancelJob", To: "start"})}}},
+			expectedErr: "scenario 'job' state 'start' has a transition on unknown operationId 'cancelJob'",
+		},
+		"unknown transition state": {
+			scenarios:   []Scenario{{Name: "job", InitialState: "start", States: map[string]ScenarioState{"start": state(nil, ScenarioTransition{On: "getJob", To: "done"})}}},
+			expectedErr: "scenario 'job' state 'start' has a transition to unknown state 'done'",
+		},
+		"invalid after": {
+			scenarios:   []Scenario{{Name: "job", InitialState: "start", States: map[string]ScenarioState{"start": state(nil, ScenarioTransition{On: "getJob", To: "start", After: -1})}}},
+			expectedErr: "scenario 'job' state 'start' has an invalid after -1, use a positive number of calls",
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Act
+			_, err := SpecToRequestStructureMap("./testdata/examplescenarios.yaml", GenerateOptions{MaxRecursionDepth: 1, Scenarios: data.scenarios})
+
+			// Assert
+			require.EqualError(t, err, data.expectedErr)
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_Scenarios(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		requests      []*http.Request
+		expectedCodes []int
+		expectedBody  string
+		expectedState map[string]string
+	}{
+		"responses until transition": {
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/jobs/1", nil),
+				httptest.NewRequest(http.MethodGet, "/jobs/1", nil),
+			},
+			expectedCodes: []int{http.StatusOK, http.StatusOK},
+			expectedBody:  `{"id":1,"status":"pending"}`,
+			expectedState: map[string]string{"job": "done", "session": "loggedOut"},
+		},
+		"response after transition": {
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/jobs/1", nil),
+				httptest.NewRequest(http.MethodGet, "/jobs/1", nil),
+				httptest.NewRequest(http.MethodGet, "/jobs/1", nil),
+			},
+			expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusOK},
+			expectedBody:  `{"id":1,"status":"done"}`,
+			expectedState: map[string]string{"job": "done", "session": "loggedOut"},
+		},
+		"status in state": {
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/me", nil),
+			},
+			expectedCodes: []int{http.StatusUnauthorized},
+			expectedBody:  `{"message":"not logged in"}`,
+			expectedState: map[string]string{"job": "pending", "session": "loggedOut"},
+		},
+		"transition on other operation": {
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodGet, "/me", nil),
+				httptest.NewRequest(http.MethodPost, "/login", nil),
+				httptest.NewRequest(http.MethodGet, "/me", nil),
+			},
+			expectedCodes: []int{http.StatusUnauthorized, http.StatusNoContent, http.StatusOK},
+			expectedBody:  `{"name":"Rex"}`,
+			expectedState: map[string]string{"job": "pending", "session": "loggedIn"},
+		},
+		"transition back": {
+			requests: []*http.Request{
+				httptest.NewRequest(http.MethodPost, "/login", nil),
+				httptest.NewRequest(http.MethodPost, "/logout", nil),
+				httptest.NewRequest(http.MethodGet, "/me", nil),
+			},
+			expectedCodes: []int{http.StatusNoContent, http.StatusNoContent, http.StatusUnauthorized},
+			expectedBody:  `{"message":"not logged in"}`,
+			expectedState: map[string]string{"job": "pending", "session": "loggedOut"},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := scenarioServer(t)
+			codes := []int{}
+			var last *httptest.ResponseRecorder
+
+			// Act
+			for _, request := range data.requests {
+				last = httptest.NewRecorder()
+				server.ServeHTTP(last, request)
+				codes = append(codes, last.Code)
+			}
+
+			// Assert
+			assert.Equal(t, data.expectedCodes, codes)
+			assert.JSONEq(t, data.expectedBody, last.Body.String())
+			assert.Equal(t, data.expectedState, server.ScenarioStates())
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_ScenarioHeaders(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server := scenarioServer(t)
+	require.NoError(t, server.SetScenarioStates(map[string]string{"job": "done"}))
+	recorder := httptest.NewRecorder()
+
+	// Act
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/jobs/1", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "done", recorder.Header().Get("X-Job-Status"))
+}
+
+func Test_Server_ServeHTTP_AdminScenarios(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		states        string
+		expectedCode  int
+		expectedBody  string
+		expectedReset string
+	}{
+		"set state": {
+			states:        `{"job": "done"}`,
+			expectedCode:  http.StatusOK,
+			expectedBody:  `{"job":"done","session":"loggedOut"}`,
+			expectedReset: `{"job":"pending","session":"loggedOut"}`,
+		},
+		"unknown scenario": {
+			states:       `{"order": "done"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"message":"unknown scenario 'order'"}`,
+		},
+		"unknown state": {
+			states:       `{"job": "failed"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"message":"unknown state 'failed' in scenario 'job'"}`,
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := scenarioServer(t)
+			recorder := httptest.NewRecorder()
+			reset := httptest.NewRecorder()
+
+			// Act
+			server.ServeHTTP(recorder, httptest.NewRequest(http.MethodPut, "/__admin/scenarios", strings.NewReader(data.states)))
+			server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/__admin/reset", nil))
+			server.ServeHTTP(reset, httptest.NewRequest(http.MethodGet, "/__admin/scenarios", nil))
+
+			// Assert
+			assert.Equal(t, data.expectedCode, recorder.Code)
+			assert.JSONEq(t, data.expectedBody, recorder.Body.String())
+			if data.expectedReset != "" {
+				assert.JSONEq(t, data.expectedReset, reset.Body.String())
+			}
+		})
+	}
+}
+
+func Test_GenerateServerFile_EmbedsScenarios(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	scenarios, err := LoadScenarios("./testdata/examplescenarios-flows.yaml")
+	require.NoError(t, err)
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplescenarios.yaml", GenerateOptions{MaxRecursionDepth: 1, Scenarios: scenarios})
+	require.NoError(t, err)
+
+	// Act
+	result, err := GenerateServerFile("http", 5000, "db.json", featureFileDataStructure)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, result, `const scenarios = {"getJob":[{"name":"job","initialState":"pending","states":["done","pending"],`)
+	assert.Contains(t, result, `"login":[{"name":"session","initialState":"loggedOut","states":["loggedIn","loggedOut"],"transitions":[{"from":"loggedOut","to":"loggedIn"}]}]`)
+}
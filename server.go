@@ -0,0 +1,543 @@
+package genmock
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type route struct {
+	method   string
+	segments []string
+	request  RequestStructure
+}
+
+type Server struct {
+	Logger          *log.Logger
+	StatusOverrides map[string]string
+	Chaos           *Chaos
+	routes          []route
+	adminRoutes     []adminRoute
+	overrides       []routeOverride
+	db              map[string][]any
+	initialDb       map[string][]any
+	requests        []RecordedRequest
+	variantCalls    map[string]int
+	seeded          map[string]bool
+	scenarios       map[string][]string
+	scenarioStates  map[string]string
+	scenarioCalls   map[string]map[string]int
+	mu              sync.Mutex
+}
+
+func NewServer(featureFileDataStructure map[string]map[string][]RequestStructure) *Server {
+	initialDb := dbEntryCollections(featureFileDataStructure)
+	server := &Server{
+		adminRoutes:  adminRoutes(featureFileDataStructure),
+		db:           cloneCollections(initialDb),
+		initialDb:    initialDb,
+		variantCalls: map[string]int{},
+		seeded:       map[string]bool{},
+		scenarios:    map[string][]string{},
+	}
+	routeMap := map[string]bool{}
+	for _, calls := range featureFileDataStructure {
+		for _, filterPaths := range calls {
+			for _, filterPath := range filterPaths {
+				if len(filterPath.DbRecords) > 0 {
+					server.seeded[filterPath.DbEntry] = true
+				}
+				path := strings.Split(filterPath.Path, "?")[0]
+				routeKey := fmt.Sprintf("%s %s", filterPath.Method, path)
+				if routeMap[routeKey] {
+					continue
+				}
+				routeMap[routeKey] = true
+				server.routes = append(server.routes, route{
+					method:   filterPath.Method,
+					segments: splitPath(path),
+					request:  filterPath,
+				})
+			}
+		}
+	}
+	server.resetScenarios()
+
+	return server
+}
+
+func (s *Server) ListenAndServe(scheme string, port int) error {
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if scheme == "https" {
+		return httpServer.ListenAndServeTLS(certFile, keyFile)
+	}
+
+	return httpServer.ListenAndServe()
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if isAdminPath(r.URL.Path) {
+		s.serveAdmin(w, r)
+		return
+	}
+	method := strings.ToLower(r.Method)
+	segments := splitPath(r.URL.Path)
+	s.logf("%s %s", r.Method, r.URL.RequestURI())
+
+	pathMatch, pathParams := s.matchRoute(method, segments)
+	if pathMatch == nil {
+		pathMatch, pathParams = s.matchRoute("", segments)
+	}
+	recorded := s.newRecord(r, pathMatch, pathParams)
+	statusWriter := &statusRecorder{ResponseWriter: w}
+	w = statusWriter
+	defer func() {
+		recorded.Status = statusWriter.statusCode
+		s.record(recorded)
+	}()
+
+	var routeRequest *RequestStructure
+	if pathMatch != nil && pathMatch.method == method {
+		routeRequest = &pathMatch.request
+	}
+	if chaos := s.chaosFor(routeRequest); chaos != nil {
+		chaosWriter, done := chaos.inject(w, r)
+		if done {
+			return
+		}
+		if truncated, ok := chaosWriter.(*truncatedWriter); ok {
+			defer truncated.abort()
+			w = truncated
+		}
+	}
+
+	if pathMatch != nil && pathMatch.method == method {
+		if statusCode, validationErrors := validateRequest(pathMatch.request, r, pathParams); len(validationErrors) > 0 {
+			writeJson(w, statusCode, map[string]any{"message": "request validation failed", "errors": validationErrors})
+			return
+		}
+		if override, ok := s.takeOverride(pathMatch); ok {
+			writeOverride(w, pathMatch, override)
+			return
+		}
+		if response, ok := s.takeScenarioResponse(pathMatch.request); ok {
+			writeScenarioResponse(w, pathMatch, response)
+			return
+		}
+		if request, responseBody, ok := s.selectedResponse(pathMatch, r); ok {
+			s.writeStatic(w, r, request, responseBody)
+			return
+		}
+		if pathMatch.request.Crud != nil {
+			s.serveCrud(w, r, pathMatch.request, pathParams)
+			return
+		}
+	}
+
+	if pathMatch != nil && pathMatch.method == method && (method != "get" || !s.seeded[pathMatch.request.DbEntry]) {
+		s.writeStatic(w, r, pathMatch.request, s.nextResponseBody(pathMatch, r))
+		return
+	}
+
+	if pathMatch != nil {
+		id, parents := collectionParams(pathMatch.request, pathParams)
+		if pathMatch.method != method {
+			s.serveCollection(w, r, pathMatch.request.DbEntry, id, parents)
+			return
+		}
+		statusCode, responseBody := s.collectionResponse(r, pathMatch.request.DbEntry, id, parents, "")
+		for name, value := range pathMatch.request.ResponseHeaders {
+			w.Header().Set(name, headerValue(name, value, responseBody, strings.Split(pathMatch.request.Path, "?")[0]))
+		}
+		writeJson(w, statusCode, responseBody)
+		return
+	}
+
+	if len(segments) == 1 || len(segments) == 2 {
+		s.mu.Lock()
+		_, ok := s.db[segments[0]]
+		s.mu.Unlock()
+		if ok {
+			id := ""
+			if len(segments) == 2 {
+				id = segments[1]
+			}
+			s.serveCollection(w, r, segments[0], id, nil)
+			return
+		}
+	}
+
+	writeJson(w, http.StatusNotFound, map[string]any{})
+}
+
+func (s *Server) matchRoute(method string, segments []string) (*route, []string) {
+	var bestMatch *route
+	var bestParams []string
+	bestScore := -1
+	for i, candidate := range s.routes {
+		if method != "" && candidate.method != method {
+			continue
+		}
+		params, score, ok := matchSegments(candidate.segments, segments)
+		if ok && score > bestScore {
+			bestMatch = &s.routes[i]
+			bestParams = params
+			bestScore = score
+		}
+	}
+
+	return bestMatch, bestParams
+}
+
+func (s *Server) nextResponseBody(matchedRoute *route, r *http.Request) any {
+	if example, ok := matchedRoute.request.ResponseExamples[preferValue(r, "example")]; ok {
+		return example
+	}
+	variants := matchedRoute.request.ResponseVariants
+	if len(variants) == 0 {
+		return matchedRoute.request.ResponseBody
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	routeKey := fmt.Sprintf("%s %s", matchedRoute.method, matchedRoute.request.Path)
+	responseBody := variants[s.variantCalls[routeKey]%len(variants)]
+	s.variantCalls[routeKey]++
+
+	return responseBody
+}
+
+func (s *Server) selectedResponse(matchedRoute *route, r *http.Request) (RequestStructure, any, bool) {
+	responseCode := preferValue(r, "code")
+	if responseCode == "" {
+		responseCode = r.URL.Query().Get("__code")
+	}
+	if responseCode == "" {
+		routeKey := fmt.Sprintf("%s %s", strings.ToUpper(matchedRoute.method), strings.Split(matchedRoute.request.Path, "?")[0])
+		responseCode = s.StatusOverrides[routeKey]
+	}
+	if responseCode == "" {
+		return RequestStructure{}, nil, false
+	}
+	for _, key := range []string{responseCode, responseCode[:1] + "XX", "default"} {
+		if responseBody, ok := matchedRoute.request.Responses[key]; ok {
+			request := matchedRoute.request
+			request.ResponseCode = responseCode
+			request.ResponseHeaders = request.StatusHeaders[key]
+			if responseCode != matchedRoute.request.ResponseCode {
+				request.ResponseContent = nil
+			}
+			return request, responseBody, true
+		}
+	}
+
+	return RequestStructure{}, nil, false
+}
+
+func (s *Server) writeStatic(w http.ResponseWriter, r *http.Request, request RequestStructure, responseBody any) {
+	statusCode, err := strconv.Atoi(request.ResponseCode)
+	if err != nil {
+		statusCode = http.StatusOK
+	}
+	if len(request.ResponseContent) > 0 {
+		content, ok := negotiateContent(r.Header.Get("Accept"), request.ResponseContent)
+		if !ok {
+			mediaTypes := []string{}
+			for _, entry := range request.ResponseContent {
+				mediaTypes = append(mediaTypes, entry.MediaType)
+			}
+			writeJson(w, http.StatusNotAcceptable, map[string]any{"message": "not acceptable", "accepted": mediaTypes})
+			return
+		}
+		if !content.Json {
+			setHeaders(w, request.ResponseHeaders)
+			writeContent(w, statusCode, content)
+			return
+		}
+	}
+	setHeaders(w, request.ResponseHeaders)
+	if responseBody == nil {
+		w.WriteHeader(statusCode)
+		return
+	}
+	writeJson(w, statusCode, responseBody)
+}
+
+func (s *Server) serveCollection(w http.ResponseWriter, r *http.Request, dbEntry string, id string, parents map[string]string) {
+	statusCode, body := s.collectionResponse(r, dbEntry, id, parents, "")
+	if r.Method == http.MethodDelete && statusCode == http.StatusOK {
+		body = map[string]any{}
+	}
+	writeJson(w, statusCode, body)
+}
+
+func (s *Server) collectionResponse(r *http.Request, dbEntry string, id string, parents map[string]string, idField string) (int, any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	collection := s.db[dbEntry]
+	index := -1
+	if id != "" {
+		for i, record := range collection {
+			if recordMap, ok := record.(map[string]any); ok && fmt.Sprint(recordMap["id"]) == id && matchesParents(recordMap, parents) {
+				index = i
+				break
+			}
+		}
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		if id == "" {
+			children := []any{}
+			for _, record := range collection {
+				if recordMap, ok := record.(map[string]any); ok && matchesParents(recordMap, parents) {
+					children = append(children, record)
+				}
+			}
+			return http.StatusOK, cloneValue(filterCollection(children, r))
+		}
+		if index < 0 {
+			return http.StatusNotFound, map[string]any{}
+		}
+		return http.StatusOK, cloneValue(collection[index])
+	case http.MethodPost:
+		record, err := readJsonObject(r)
+		if err != nil {
+			return http.StatusBadRequest, map[string]any{"error": err.Error()}
+		}
+		if _, ok := record["id"]; !ok {
+			record["id"] = nextId(collection)
+		}
+		for field, value := range parents {
+			if _, ok := record[field]; !ok {
+				record[field] = pathValue(value)
+			}
+		}
+		if _, ok := record[idField]; idField != "" && !ok {
+			record[idField] = record["id"]
+		}
+		s.db[dbEntry] = append(collection, record)
+		return http.StatusCreated, cloneValue(record)
+	case http.MethodPut, http.MethodPatch:
+		if index < 0 {
+			return http.StatusNotFound, map[string]any{}
+		}
+		record, err := readJsonObject(r)
+		if err != nil {
+			return http.StatusBadRequest, map[string]any{"error": err.Error()}
+		}
+		if r.Method == http.MethodPatch {
+			if existing, ok := collection[index].(map[string]any); ok {
+				for k, v := range record {
+					existing[k] = v
+				}
+				record = existing
+			}
+		}
+		record["id"] = collection[index].(map[string]any)["id"]
+		if r.Method == http.MethodPut {
+			for field, value := range parents {
+				if _, ok := record[field]; !ok {
+					record[field] = pathValue(value)
+				}
+			}
+			if _, ok := record[idField]; idField != "" && !ok {
+				record[idField] = record["id"]
+			}
+		}
+		collection[index] = record
+		return http.StatusOK, cloneValue(record)
+	case http.MethodDelete:
+		if index < 0 {
+			return http.StatusNotFound, map[string]any{}
+		}
+		record := collection[index]
+		s.db[dbEntry] = append(collection[:index], collection[index+1:]...)
+		return http.StatusOK, record
+	}
+
+	return http.StatusMethodNotAllowed, map[string]any{}
+}
+
+func (s *Server) logf(format string, args ...any) {
+	if s.Logger != nil {
+		s.Logger.Printf(format, args...)
+	}
+}
+
+func collectionParams(request RequestStructure, pathParams []string) (string, map[string]string) {
+	if len(request.DbParents) == 0 {
+		if len(pathParams) == 0 {
+			return "", nil
+		}
+		return pathParams[len(pathParams)-1], nil
+	}
+	id := ""
+	parents := map[string]string{}
+	for i, param := range request.RequestParams {
+		if i >= len(pathParams) {
+			break
+		}
+		if collection, ok := request.DbParents[param]; ok {
+			parents[parentField(param, collection)] = pathParams[i]
+		} else {
+			id = pathParams[i]
+		}
+	}
+
+	return id, parents
+}
+
+func matchesParents(record map[string]any, parents map[string]string) bool {
+	for field, value := range parents {
+		if fmt.Sprint(record[field]) != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func pathValue(value string) any {
+	if number, err := strconv.Atoi(value); err == nil {
+		return number
+	}
+
+	return value
+}
+
+func preferValue(r *http.Request, key string) string {
+	for _, header := range r.Header.Values("Prefer") {
+		for preference := range strings.FieldsFuncSeq(header, func(c rune) bool { return c == ',' || c == ';' || c == ' ' }) {
+			name, value, ok := strings.Cut(preference, "=")
+			if ok && name == key {
+				return strings.Trim(value, `"`)
+			}
+		}
+	}
+
+	return ""
+}
+
+func splitPath(path string) []string {
+	segments := []string{}
+	for segment := range strings.SplitSeq(path, "/") {
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+
+	return segments
+}
+
+func matchSegments(pattern []string, segments []string) ([]string, int, bool) {
+	if len(pattern) != len(segments) {
+		return nil, 0, false
+	}
+	params := []string{}
+	score := 0
+	for i, segment := range pattern {
+		if strings.HasPrefix(segment, ":") {
+			params = append(params, segments[i])
+			continue
+		}
+		if segment != segments[i] {
+			return nil, 0, false
+		}
+		score++
+	}
+
+	return params, score, true
+}
+
+func filterCollection(collection []any, r *http.Request) []any {
+	query := r.URL.Query()
+	result := []any{}
+	for _, record := range collection {
+		recordMap, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		matches := true
+		for key, values := range query {
+			if strings.HasPrefix(key, "_") {
+				continue
+			}
+			if value, ok := recordMap[key]; !ok || fmt.Sprint(value) != values[0] {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			result = append(result, record)
+		}
+	}
+
+	return result
+}
+
+func nextId(collection []any) any {
+	if len(collection) == 0 {
+		return 1
+	}
+	maxId := math.Inf(-1)
+	for _, record := range collection {
+		recordMap, ok := record.(map[string]any)
+		if !ok {
+			continue
+		}
+		id, ok := recordMap["id"].(float64)
+		if !ok {
+			if intId, isInt := recordMap["id"].(int); isInt {
+				id = float64(intId)
+			} else {
+				return RandStringBytesRmndr(7)
+			}
+		}
+		maxId = math.Max(maxId, id)
+	}
+
+	if math.IsInf(maxId, -1) {
+		return 1
+	}
+
+	return int(maxId) + 1
+}
+
+func readJsonObject(r *http.Request) (map[string]any, error) {
+	record := map[string]any{}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return record, err
+	}
+	if len(body) == 0 {
+		return record, nil
+	}
+	err = json.Unmarshal(body, &record)
+
+	return record, err
+}
+
+func setHeaders(w http.ResponseWriter, headers map[string]string) {
+	for name, value := range headers {
+		w.Header().Set(name, value)
+	}
+}
+
+func writeJson(w http.ResponseWriter, statusCode int, body any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,385 @@
+package genmock
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func Test_Server_ServeHTTP_ReturnsSpecResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		method       string
+		path         string
+		expectedCode int
+		expectedBody any
+	}{
+		"static item route": {
+			method:       http.MethodGet,
+			path:         "/orders/42",
+			expectedCode: http.StatusOK,
+			expectedBody: map[string]any{
+				"created_at": "", "id": "", "items": []any{
+					map[string]any{"product_id": "", "quantity": float64(1)},
+				}, "status": "", "total_amount": float64(0),
+			},
+		},
+		"static route with query": {
+			method:       http.MethodGet,
+			path:         "/products?category=shoes",
+			expectedCode: http.StatusOK,
+			expectedBody: []any{
+				map[string]any{
+					"category": "", "created_at": "", "description": "", "id": "", "image_url": "", "name": "", "price": float64(0), "stock": float64(0), "updated_at": "",
+				},
+			},
+		},
+		"unknown route": {
+			method:       http.MethodGet,
+			path:         "/unknown/route",
+			expectedCode: http.StatusNotFound,
+			expectedBody: map[string]any{},
+		},
+	}
+
+	featureFileDataStructure, err := SpecV3toRequestStructureMap("./testdata/examplev3.yaml", 1, false)
+	require.NoError(t, err)
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := NewServer(featureFileDataStructure)
+			recorder := httptest.NewRecorder()
+
+			// Act
+			server.ServeHTTP(recorder, httptest.NewRequest(data.method, data.path, nil))
+
+			// Assert
+			var body any
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			assert.Equal(t, data.expectedCode, recorder.Code)
+			assert.Equal(t, data.expectedBody, body)
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_FallsBackToCollection(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecV3toRequestStructureMap("./testdata/examplev3.yaml", 1, false)
+	require.NoError(t, err)
+	server := httptest.NewServer(NewServer(featureFileDataStructure))
+	defer server.Close()
+	call := func(method string, path string, body string) (int, string) {
+		request, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		response, err := http.DefaultClient.Do(request)
+		require.NoError(t, err)
+		defer func() {
+			_ = response.Body.Close()
+		}()
+		responseBody, err := io.ReadAll(response.Body)
+		require.NoError(t, err)
+
+		return response.StatusCode, strings.TrimSpace(string(responseBody))
+	}
+
+	// Act
+	createCode, createBody := call(http.MethodPost, "/products", `{"name":"chair"}`)
+	patchCode, patchBody := call(http.MethodPatch, "/products/1", `{"stock":3}`)
+	getCode, getBody := call(http.MethodGet, "/auth-login", "")
+	deleteCode, _ := call(http.MethodDelete, "/products/1", "")
+	deleteAgainCode, _ := call(http.MethodDelete, "/products/1", "")
+
+	// Assert
+	assert.Equal(t, http.StatusCreated, createCode)
+	assert.JSONEq(t, `{"id":1,"name":"chair"}`, createBody)
+	assert.Equal(t, http.StatusOK, patchCode)
+	assert.JSONEq(t, `{"id":1,"name":"chair","stock":3}`, patchBody)
+	assert.Equal(t, http.StatusOK, getCode)
+	assert.JSONEq(t, `[]`, getBody)
+	assert.Equal(t, http.StatusOK, deleteCode)
+	assert.Equal(t, http.StatusNotFound, deleteAgainCode)
+}
+
+func Test_Server_ServeHTTP_CyclesResponseVariants(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplepolymorphic.yaml", GenerateOptions{MaxRecursionDepth: 1, AllBranches: true})
+	require.NoError(t, err)
+	server := NewServer(featureFileDataStructure)
+	responseTypes := []any{}
+
+	// Act
+	for range 3 {
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/payment-methods/1", nil))
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+		responseTypes = append(responseTypes, body["type"])
+	}
+
+	// Assert
+	assert.Equal(t, []any{"card", "iban", "card"}, responseTypes)
+}
+
+func Test_Server_ServeHTTP_ReturnsPreferredExample(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		prefer        string
+		expectedTitle any
+	}{
+		"no preference":     {prefer: "", expectedTitle: "Dune"},
+		"preferred example": {prefer: "example=poetry", expectedTitle: "Leaves of Grass"},
+		"unknown example":   {prefer: "example=comic", expectedTitle: "Dune"},
+	}
+
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/exampleexamples.yaml", GenerateOptions{MaxRecursionDepth: 1, GenExamples: true})
+	require.NoError(t, err)
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := NewServer(featureFileDataStructure)
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/books/b-1", nil)
+			request.Header.Set("Prefer", data.prefer)
+
+			// Act
+			server.ServeHTTP(recorder, request)
+
+			// Assert
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			assert.Equal(t, data.expectedTitle, body["title"])
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_SeededHeadersFollowRecords(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		path               string
+		expectedTotalCount string
+	}{
+		"collection": {path: "/pets", expectedTotalCount: "3"},
+		"record":     {path: "/pets/2"},
+	}
+
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/exampledbheaders.yaml", GenerateOptions{MaxRecursionDepth: 1, GenExamples: true, DbRecords: 3})
+	require.NoError(t, err)
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := NewServer(featureFileDataStructure)
+			recorder := httptest.NewRecorder()
+
+			// Act
+			server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, data.path, nil))
+
+			// Assert
+			var body any
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			assert.Equal(t, data.expectedTotalCount, recorder.Header().Get("X-Total-Count"))
+			assert.Equal(t, headerValue("ETag", "", body, ""), recorder.Header().Get("ETag"))
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_ServesSeededRelations(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		path         string
+		expectedCode int
+		expectedBody string
+	}{
+		"nested children": {
+			path:         "/customers/2/orders",
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"id":"2","customerId":2,"productCode":"2","supplier":"2"}]`,
+		},
+		"linked parent": {
+			path:         "/customers/2",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"id":2,"name":""}`,
+		},
+		"unknown parent": {
+			path:         "/customers/9/orders",
+			expectedCode: http.StatusOK,
+			expectedBody: `[]`,
+		},
+		"unknown record": {
+			path:         "/customers/9",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{}`,
+		},
+	}
+
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/exampledbrelations.yaml", GenerateOptions{MaxRecursionDepth: 1, DbRecords: 3})
+	require.NoError(t, err)
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := NewServer(featureFileDataStructure)
+			recorder := httptest.NewRecorder()
+
+			// Act
+			server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, data.path, nil))
+
+			// Assert
+			assert.Equal(t, data.expectedCode, recorder.Code)
+			assert.JSONEq(t, data.expectedBody, recorder.Body.String())
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_SelectsResponseStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		method          string
+		path            string
+		prefer          string
+		statusOverrides map[string]string
+		expectedCode    int
+		expectedBody    string
+	}{
+		"declared success": {
+			method:       http.MethodDelete,
+			path:         "/pets/1",
+			expectedCode: http.StatusNoContent,
+		},
+		"prefer header": {
+			method:       http.MethodGet,
+			path:         "/pets/1",
+			prefer:       "code=404",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"code":0,"message":""}`,
+		},
+		"query parameter range": {
+			method:       http.MethodGet,
+			path:         "/pets/1?__code=503",
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"code":0,"message":""}`,
+		},
+		"default response": {
+			method:       http.MethodGet,
+			path:         "/pets/1",
+			prefer:       "code=418",
+			expectedCode: http.StatusTeapot,
+			expectedBody: `{"reason":""}`,
+		},
+		"override file": {
+			method:          http.MethodDelete,
+			path:            "/pets/1",
+			statusOverrides: map[string]string{"DELETE /pets/:petId": "409"},
+			expectedCode:    http.StatusConflict,
+		},
+		"undeclared status": {
+			method:       http.MethodDelete,
+			path:         "/pets/1",
+			prefer:       "code=500",
+			expectedCode: http.StatusNoContent,
+		},
+	}
+
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplestatuses.yaml", GenerateOptions{MaxRecursionDepth: 1, AllResponses: true})
+	require.NoError(t, err)
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := NewServer(featureFileDataStructure)
+			server.StatusOverrides = data.statusOverrides
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(data.method, data.path, nil)
+			request.Header.Set("Prefer", data.prefer)
+
+			// Act
+			server.ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, data.expectedCode, recorder.Code)
+			if data.expectedBody != "" {
+				assert.JSONEq(t, data.expectedBody, recorder.Body.String())
+			}
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_SetsResponseHeaders(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		method          string
+		prefer          string
+		expectedCode    int
+		expectedHeaders map[string]string
+	}{
+		"list response": {
+			method:          http.MethodGet,
+			expectedCode:    http.StatusOK,
+			expectedHeaders: map[string]string{"ETag": `"8ff3ee16be0d37f7"`, "X-RateLimit-Remaining": "99", "X-Total-Count": "2"},
+		},
+		"created response": {
+			method:          http.MethodPost,
+			expectedCode:    http.StatusCreated,
+			expectedHeaders: map[string]string{"Location": "/pets/3", "Retry-After": ""},
+		},
+		"selected response": {
+			method:          http.MethodPost,
+			prefer:          "code=429",
+			expectedCode:    http.StatusTooManyRequests,
+			expectedHeaders: map[string]string{"Location": "", "Retry-After": "30"},
+		},
+	}
+
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/exampleheaders.yaml", GenerateOptions{MaxRecursionDepth: 1, GenExamples: true, AllResponses: true})
+	require.NoError(t, err)
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			server := NewServer(featureFileDataStructure)
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(data.method, "/pets", nil)
+			request.Header.Set("Prefer", data.prefer)
+
+			// Act
+			server.ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, data.expectedCode, recorder.Code)
+			for header, value := range data.expectedHeaders {
+				assert.Equal(t, value, recorder.Header().Get(header), header)
+			}
+		})
+	}
+}
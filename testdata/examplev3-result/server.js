@@ -23,6 +23,650 @@ function checkWriteToDb() {
 	}
 }
 
+const variantCalls = {};
+function nextVariant(route, variants) {
+	variantCalls[route] = (variantCalls[route] || 0) + 1;
+	return variants[(variantCalls[route] - 1) % variants.length];
+}
+
+function preferredExample(req, examples, fallback) {
+	const prefer = /example=([^;,\s]+)/.exec(req.get('Prefer') || '');
+	if (prefer && prefer[1] in examples) {
+		return examples[prefer[1]];
+	}
+	return fallback;
+}
+
+function seededRecords(req, collection, parents, idParam) {
+	const records = (router.db.get(collection).value() || []).filter((record) =>
+		Object.keys(parents).every((field) => String(record[field]) === req.params[parents[field]]) &&
+		Object.keys(req.query).every((key) => key.startsWith('_') || String(record[key]) === String(req.query[key])));
+	if (idParam) {
+		return records.find((record) => String(record.id) === req.params[idParam]);
+	}
+	return records;
+}
+
+function shapeEntity(template, entity) {
+	if (Array.isArray(template)) {
+		return Array.isArray(entity) ? entity.map((item) => template.length > 0 ? shapeEntity(template[0], item) : item) : entity;
+	}
+	if (template !== null && typeof template === 'object') {
+		if (entity === null || typeof entity !== 'object' || Array.isArray(entity)) {
+			return entity;
+		}
+		const shaped = {};
+		Object.keys(template).filter((key) => key in entity).forEach((key) => {
+			shaped[key] = shapeEntity(template[key], entity[key]);
+		});
+		return shaped;
+	}
+	return entity;
+}
+
+function nextId(records) {
+	if (records.every((record) => typeof record.id === 'number')) {
+		return Math.max(0, ...records.map((record) => record.id)) + 1;
+	}
+	return Math.random().toString(36).slice(2, 9);
+}
+
+function crudResponse(req, action, collection, parents, idParam, statusCode, template) {
+	const state = router.db.getState();
+	const records = state[collection] = state[collection] || [];
+	const inParents = (record) => Object.keys(parents).every((field) => String(record[field]) === req.params[parents[field]]);
+	const idField = idParam !== 'id' && template !== null && typeof template === 'object' && !Array.isArray(template) && idParam in template ? idParam : '';
+	const shaped = (entity) => [statusCode, template === undefined ? undefined : shapeEntity(template, entity)];
+	const complete = (record) => {
+		Object.keys(parents).forEach((field) => {
+			if (record[field] === undefined) {
+				record[field] = /^-?\d+$/.test(req.params[parents[field]]) ? Number(req.params[parents[field]]) : req.params[parents[field]];
+			}
+		});
+		if (idField && record[idField] === undefined) {
+			record[idField] = record.id;
+		}
+		return record;
+	};
+	if (action === 'list') {
+		return shaped(records.filter((record) => inParents(record) &&
+			Object.keys(req.query).every((key) => key.startsWith('_') || String(record[key]) === String(req.query[key]))));
+	}
+	if (action === 'create') {
+		const record = Object.assign({}, req.body);
+		if (record.id === undefined) {
+			record.id = nextId(records);
+		}
+		records.push(complete(record));
+		router.db.write();
+		return shaped(record);
+	}
+	const index = records.findIndex((record) => String(record.id) === req.params[idParam] && inParents(record));
+	if (index < 0) {
+		return [404, {}];
+	}
+	const record = records[index];
+	if (action === 'read') {
+		return shaped(record);
+	}
+	if (action === 'delete') {
+		records.splice(index, 1);
+		router.db.write();
+		return shaped(record);
+	}
+	records[index] = action === 'update' ? Object.assign(record, req.body, {id: record.id}) : complete(Object.assign({}, req.body, {id: record.id}));
+	router.db.write();
+	return shaped(records[index]);
+}
+
+function crudHeaders(headers, body, path) {
+	const values = {};
+	Object.keys(headers).forEach((name) => {
+		values[name] = headers[name];
+		if (name.toLowerCase() === 'etag' && body !== undefined) {
+			let hash = 0xcbf29ce484222325n;
+			for (const byte of Buffer.from(JSON.stringify(body))) {
+				hash = BigInt.asUintN(64, (hash ^ BigInt(byte)) * 0x100000001b3n);
+			}
+			values[name] = '"' + hash.toString(16) + '"';
+		}
+		if (name.toLowerCase() === 'x-total-count' && Array.isArray(body)) {
+			values[name] = String(body.length);
+		}
+		if (name.toLowerCase() === 'location' && body && body.id !== undefined && !path.includes(':')) {
+			values[name] = path.replace(/\/$/, '') + '/' + body.id;
+		}
+	});
+	return values;
+}
+
+const statusOverrides = process.env.STATUS_FILE ? JSON.parse(fs.readFileSync(process.env.STATUS_FILE)) : {};
+function selectResponse(req, route, statusCode, responseBody, responseHeaders, responses, statusHeaders) {
+	const prefer = /(?:^|[;,\s])code=(\d{3})/.exec(req.get('Prefer') || '');
+	const code = String((prefer && prefer[1]) || req.query.__code || statusOverrides[route] || '');
+	const key = [code, code.charAt(0) + 'XX', 'default'].find((candidate) => code && candidate in responses);
+	if (key === undefined) {
+		return [statusCode, responseBody, responseHeaders];
+	}
+	return [Number(code), responses[key] === null ? undefined : responses[key], statusHeaders[key] || {}];
+}
+
+function sendContent(req, res, statusCode, content) {
+	const mediaTypes = content.map((entry) => entry.mediaType);
+	const mediaType = req.accepts(mediaTypes);
+	if (!mediaType) {
+		res.status(406).json({message: 'not acceptable', accepted: mediaTypes});
+		return true;
+	}
+	const entry = content.find((candidate) => candidate.mediaType === mediaType);
+	if (entry.json) {
+		return false;
+	}
+	res.status(statusCode).type(entry.contentType).send(entry.binary ? Buffer.from(entry.body || '', 'base64') : entry.body || '');
+	return true;
+}
+
+const formatChecks = {
+	'date-time': (value) => /^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})$/i.test(value) && !isNaN(Date.parse(value)),
+	'date': (value) => /^\d{4}-\d{2}-\d{2}$/.test(value) && !isNaN(Date.parse(value)),
+	'uuid': (value) => /^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$/i.test(value),
+	'email': (value) => /^[^\s@]+@[^\s@]+$/.test(value),
+	'ipv4': (value) => require('net').isIPv4(value),
+	'ipv6': (value) => require('net').isIPv6(value),
+	'uri': (value) => {
+		try {
+			return new URL(value).protocol !== '';
+		} catch (err) {
+			return false;
+		}
+	},
+};
+
+function pointerToken(token) {
+	return String(token).replace(/~/g, '~0').replace(/\//g, '~1');
+}
+
+function validationErrors(rule, value, pointer) {
+	if (!rule) {
+		return [];
+	}
+	const valueType = value === null || value === undefined ? 'null' : Array.isArray(value) ? 'array' : Number.isInteger(value) ? 'integer' : typeof value;
+	if (valueType === 'null') {
+		return rule.nullable || !rule.type ? [] : [{pointer, message: 'must not be null'}];
+	}
+	if (rule.type && !rule.type.includes(valueType) && !(valueType === 'integer' && rule.type.includes('number'))) {
+		return [{pointer, message: 'must be of type ' + rule.type.join(' or ')}];
+	}
+	const errors = [];
+	const fail = (pointer, message) => errors.push({pointer, message});
+	if (rule.enum && !rule.enum.some((enumValue) => JSON.stringify(enumValue) === JSON.stringify(value))) {
+		fail(pointer, 'must be one of ' + JSON.stringify(rule.enum));
+	}
+	if (valueType === 'string') {
+		const length = [...value].length;
+		if (rule.minLength !== undefined && length < rule.minLength) {
+			fail(pointer, 'must be at least ' + rule.minLength + ' characters long');
+		}
+		if (rule.maxLength !== undefined && length > rule.maxLength) {
+			fail(pointer, 'must be at most ' + rule.maxLength + ' characters long');
+		}
+		if (rule.pattern && !new RegExp(rule.pattern).test(value)) {
+			fail(pointer, "must match pattern '" + rule.pattern + "'");
+		}
+		if (rule.format in formatChecks && !formatChecks[rule.format](value)) {
+			fail(pointer, 'must be a valid ' + rule.format);
+		}
+	}
+	if (valueType === 'integer' || valueType === 'number') {
+		if (rule.minimum !== undefined && value < rule.minimum) {
+			fail(pointer, 'must be greater than or equal to ' + rule.minimum);
+		}
+		if (rule.maximum !== undefined && value > rule.maximum) {
+			fail(pointer, 'must be less than or equal to ' + rule.maximum);
+		}
+	}
+	if (valueType === 'array') {
+		if (rule.minItems !== undefined && value.length < rule.minItems) {
+			fail(pointer, 'must contain at least ' + rule.minItems + ' items');
+		}
+		if (rule.maxItems !== undefined && value.length > rule.maxItems) {
+			fail(pointer, 'must contain at most ' + rule.maxItems + ' items');
+		}
+		value.forEach((item, i) => errors.push(...validationErrors(rule.items, item, pointer + '/' + i)));
+	}
+	if (valueType === 'object') {
+		(rule.required || []).filter((key) => !(key in value)).forEach((key) => fail(pointer + '/' + pointerToken(key), 'is required'));
+		Object.keys(value).sort().forEach((key) => {
+			const known = rule.properties && Object.prototype.hasOwnProperty.call(rule.properties, key);
+			if (!known && rule.additionalProperties === false) {
+				fail(pointer + '/' + pointerToken(key), 'is not allowed');
+				return;
+			}
+			errors.push(...validationErrors(known ? rule.properties[key] : undefined, value[key], pointer + '/' + pointerToken(key)));
+		});
+	}
+	(rule.allOf || []).forEach((allOfRule) => errors.push(...validationErrors(allOfRule, value, pointer)));
+	if (rule.anyOf && !rule.anyOf.some((anyOfRule) => validationErrors(anyOfRule, value, pointer).length === 0)) {
+		fail(pointer, 'must match one of the allowed schemas');
+	}
+	if (rule.oneOf && rule.oneOf.filter((oneOfRule) => validationErrors(oneOfRule, value, pointer).length === 0).length !== 1) {
+		fail(pointer, 'must match exactly one of the allowed schemas');
+	}
+	return errors;
+}
+
+function parameterValue(rule, values) {
+	if (!rule || !rule.type) {
+		return values[0];
+	}
+	if (rule.type.includes('array')) {
+		return (values.length === 1 ? values[0].split(',') : values).map((value) => parameterValue(rule.items, [value]));
+	}
+	if ((rule.type.includes('integer') || rule.type.includes('number')) && values[0].trim() !== '' && !isNaN(Number(values[0]))) {
+		return Number(values[0]);
+	}
+	if (rule.type.includes('boolean') && (values[0] === 'true' || values[0] === 'false')) {
+		return values[0] === 'true';
+	}
+	return values[0];
+}
+
+function validateRequest(req, res, validation, params = req.params) {
+	const errors = [];
+	(validation.parameters || []).forEach((parameter) => {
+		const pointer = '/' + parameter.in + '/' + pointerToken(parameter.name);
+		let values = [];
+		if (parameter.in === 'path' && params[parameter.name] !== undefined) {
+			values = [params[parameter.name]];
+		} else if (parameter.in === 'query' && req.query[parameter.name] !== undefined) {
+			values = [].concat(req.query[parameter.name]);
+		} else if (parameter.in === 'header' && req.get(parameter.name) !== undefined) {
+			values = [req.get(parameter.name)];
+		} else if (parameter.in === 'cookie') {
+			const cookie = (req.get('Cookie') || '').split(';').map((pair) => pair.trim().split('=')).find(([name]) => name === parameter.name);
+			values = cookie ? [cookie.slice(1).join('=')] : [];
+		}
+		if (values.length === 0) {
+			if (parameter.required) {
+				errors.push({pointer, message: 'is required'});
+			}
+			return;
+		}
+		errors.push(...validationErrors(parameter.schema, parameterValue(parameter.schema, values), pointer));
+	});
+	if (errors.length > 0) {
+		res.status(400).json({message: 'request validation failed', errors});
+		return false;
+	}
+	if (Number(req.get('Content-Length') || 0) === 0 && req.get('Transfer-Encoding') === undefined) {
+		if (validation.bodyRequired) {
+			res.status(400).json({message: 'request validation failed', errors: [{pointer: '/body', message: 'is required'}]});
+			return false;
+		}
+		return true;
+	}
+	const bodyErrors = validationErrors(validation.body, req.body, '/body');
+	if (bodyErrors.length > 0) {
+		res.status(422).json({message: 'request validation failed', errors: bodyErrors});
+		return false;
+	}
+	return true;
+}
+
+const adminRoutes = [{"method":"GET","path":"/addresses","dbEntry":"addresses","responseCode":"200"},{"method":"GET","path":"/cart","dbEntry":"cart","responseCode":"200"},{"method":"GET","path":"/orders","dbEntry":"orders","responseCode":"200"},{"method":"GET","path":"/orders/:orderId","dbEntry":"orders","responseCode":"200"},{"method":"GET","path":"/products","dbEntry":"products","responseCode":"200"},{"method":"GET","path":"/products/:id","dbEntry":"products","responseCode":"200"},{"method":"POST","path":"/addresses","dbEntry":"addresses","responseCode":"201"},{"method":"POST","path":"/auth/login","dbEntry":"auth-login","responseCode":"200"},{"method":"POST","path":"/auth/register","dbEntry":"auth-register","responseCode":"201"},{"method":"POST","path":"/cart/items","dbEntry":"cart-items","responseCode":"200"},{"method":"POST","path":"/checkout","dbEntry":"checkout","responseCode":"201"}];
+const initialDb = JSON.stringify(db);
+const journal = [];
+let overrides = [];
+const scenarios = {};
+const scenarioNames = {};
+Object.values(scenarios).forEach((operationScenarios) => operationScenarios.forEach((scenario) => {
+	scenarioNames[scenario.name] = scenario;
+}));
+let scenarioStates = {};
+let scenarioCalls = {};
+
+function resetScenarios() {
+	scenarioStates = {};
+	scenarioCalls = {};
+	Object.values(scenarioNames).forEach((scenario) => {
+		scenarioStates[scenario.name] = scenario.initialState;
+	});
+}
+resetScenarios();
+
+function takeScenarioResponse(operationId) {
+	let response;
+	(scenarios[operationId] || []).forEach((scenario) => {
+		const state = scenarioStates[scenario.name];
+		if (response === undefined && scenario.responses && state in scenario.responses) {
+			response = Object.assign({}, scenario.responses[state]);
+		}
+		const transition = (scenario.transitions || []).find((candidate) => candidate.from === state);
+		if (transition === undefined) {
+			return;
+		}
+		scenarioCalls[scenario.name] = scenarioCalls[scenario.name] || {};
+		scenarioCalls[scenario.name][operationId] = (scenarioCalls[scenario.name][operationId] || 0) + 1;
+		if (scenarioCalls[scenario.name][operationId] >= Math.max(transition.after || 0, 1)) {
+			scenarioStates[scenario.name] = transition.to;
+			delete scenarioCalls[scenario.name];
+		}
+	});
+	return response;
+}
+
+function matchRoute(method, path) {
+	const segments = path.split('/').filter(Boolean);
+	let match;
+	let matchScore = -1;
+	adminRoutes.filter((route) => route.method === method).forEach((route) => {
+		const pattern = route.path.split('/').filter(Boolean);
+		const score = pattern.filter((segment) => !segment.startsWith(':')).length;
+		if (pattern.length === segments.length && score > matchScore && pattern.every((segment, i) => segment.startsWith(':') || segment === segments[i])) {
+			match = route;
+			matchScore = score;
+		}
+	});
+	return match;
+}
+
+function routeParams(route, path) {
+	const segments = path.split('/').filter(Boolean);
+	const params = {};
+	route.path.split('/').filter(Boolean).forEach((segment, i) => {
+		if (segment.startsWith(':')) {
+			params[segment.slice(1)] = decodeURIComponent(segments[i]);
+		}
+	});
+	return params;
+}
+
+function jsonSubset(expected, actual) {
+	if (expected !== null && typeof expected === 'object') {
+		if (actual === null || typeof actual !== 'object' || Array.isArray(expected) !== Array.isArray(actual) || (Array.isArray(expected) && expected.length !== actual.length)) {
+			return false;
+		}
+		return Object.keys(expected).every((key) => key in actual && jsonSubset(expected[key], actual[key]));
+	}
+	return expected === actual;
+}
+
+function bodyMatches(expected, body) {
+	let actual;
+	try {
+		actual = JSON.parse(body);
+	} catch (err) {
+		return expected === body;
+	}
+	if (typeof expected === 'string') {
+		try {
+			expected = JSON.parse(expected);
+		} catch (err) {
+			return expected === body;
+		}
+	}
+	return jsonSubset(expected, actual);
+}
+
+function matchesRequest(matcher, entry) {
+	if (matcher.method && String(matcher.method).toUpperCase() !== entry.method) {
+		return false;
+	}
+	if (matcher.operationId && matcher.operationId !== entry.operationId) {
+		return false;
+	}
+	if (matcher.path) {
+		const pattern = String(matcher.path).replace(/\{([^}]+)\}/g, ':$1').split('/').filter(Boolean);
+		const segments = entry.path.split('/').filter(Boolean);
+		if (pattern.length !== segments.length || !pattern.every((segment, i) => segment.startsWith(':') || segment === segments[i])) {
+			return false;
+		}
+	}
+	const query = new URLSearchParams(entry.query);
+	if (Object.keys(matcher.query || {}).some((name) => query.get(name) !== String(matcher.query[name]))) {
+		return false;
+	}
+	if (Object.keys(matcher.headers || {}).some((name) => entry.headers[name.toLowerCase()] !== String(matcher.headers[name]))) {
+		return false;
+	}
+	return matcher.body === undefined || matcher.body === null || bodyMatches(matcher.body, entry.body);
+}
+
+function describeMatcher(matcher) {
+	const parts = [matcher.method && String(matcher.method).toUpperCase(), matcher.path, matcher.operationId && 'operationId=' + matcher.operationId].filter(Boolean);
+	return parts.length > 0 ? parts.join(' ') : 'any request';
+}
+
+function harLog(entries) {
+	const nameValues = (values) => Object.keys(values).sort().map((name) => ({name, value: String(values[name])}));
+	return {log: {version: '1.2', creator: {name: 'genmock', version: ''}, entries: entries.map((entry) => {
+		const request = {method: entry.method, url: entry.url, httpVersion: 'HTTP/1.1', cookies: [], headers: nameValues(entry.headers), queryString: [...new URLSearchParams(entry.query)].map(([name, value]) => ({name, value})), headersSize: -1, bodySize: Buffer.byteLength(entry.body)};
+		if (entry.body) {
+			request.postData = {mimeType: entry.headers['content-type'] || '', text: entry.body};
+		}
+		return {
+			startedDateTime: entry.timestamp,
+			time: 0,
+			request,
+			response: {status: entry.status, statusText: require('http').STATUS_CODES[entry.status] || '', httpVersion: 'HTTP/1.1', cookies: [], headers: [], content: {size: 0, mimeType: ''}, redirectURL: '', headersSize: -1, bodySize: -1},
+			cache: {},
+			timings: {send: 0, wait: 0, receive: 0},
+		};
+	})}};
+}
+
+server.use((req, res, next) => {
+	if (!req.path.startsWith('/__admin')) {
+		const route = matchRoute(req.method, req.path);
+		const params = route ? routeParams(route, req.path) : {};
+		const entry = {
+			method: req.method,
+			path: req.path,
+			query: req.originalUrl.split('?').slice(1).join('?'),
+			url: req.protocol + '://' + req.get('host') + req.originalUrl,
+			params: Object.keys(params).length > 0 ? params : undefined,
+			headers: Object.assign({}, req.headers),
+			body: req.body && Object.keys(req.body).length > 0 ? JSON.stringify(req.body) : '',
+			route: route ? route.method + ' ' + route.path : undefined,
+			operationId: route ? route.operationId : undefined,
+			status: 0,
+			timestamp: new Date().toISOString(),
+		};
+		journal.push(entry);
+		res.on('finish', () => {
+			entry.status = res.statusCode;
+		});
+	}
+	next();
+});
+
+server.get('/__admin/routes', (req, res) => {
+	res.json(adminRoutes);
+});
+
+server.get('/__admin/overrides', (req, res) => {
+	res.json(overrides);
+});
+
+server.post('/__admin/overrides', (req, res) => {
+	const override = Object.assign({}, req.body, {method: String(req.body.method || '').toUpperCase(), path: String(req.body.path || '').replace(/\{([^}]+)\}/g, ':$1')});
+	if (!adminRoutes.some((route) => route.method === override.method && route.path === override.path)) {
+		res.status(400).json({message: "unknown route '" + override.method + ' ' + override.path + "', see /__admin/routes for the mocked routes"});
+		return;
+	}
+	if (override.status !== undefined && !(override.status >= 100 && override.status <= 599)) {
+		res.status(400).json({message: 'invalid status ' + override.status});
+		return;
+	}
+	if (override.times !== undefined && !(override.times >= 0)) {
+		res.status(400).json({message: 'invalid times ' + override.times + ', use a positive number of calls'});
+		return;
+	}
+	overrides.push(override);
+	res.status(201).json(override);
+});
+
+server.delete('/__admin/overrides', (req, res) => {
+	overrides = [];
+	res.status(204).end();
+});
+
+server.post('/__admin/reset', (req, res) => {
+	router.db.setState(JSON.parse(initialDb));
+	router.db.write();
+	resetScenarios();
+	res.status(204).end();
+});
+
+server.get('/__admin/scenarios', (req, res) => {
+	res.json(scenarioStates);
+});
+
+server.put('/__admin/scenarios', (req, res) => {
+	const states = req.body || {};
+	const unknown = Object.keys(states).find((name) => !(name in scenarioNames) || !scenarioNames[name].states.includes(states[name]));
+	if (unknown !== undefined) {
+		const message = unknown in scenarioNames ? "unknown state '" + states[unknown] + "' in scenario '" + unknown + "'" : "unknown scenario '" + unknown + "'";
+		res.status(400).json({message});
+		return;
+	}
+	Object.keys(states).forEach((name) => {
+		scenarioStates[name] = states[name];
+		delete scenarioCalls[name];
+	});
+	res.json(scenarioStates);
+});
+
+server.get('/__admin/requests', (req, res) => {
+	res.json(journal.filter((entry) => matchesRequest({method: req.query.method, path: req.query.path, operationId: req.query.operationId}, entry)));
+});
+
+server.post('/__admin/requests/find', (req, res) => {
+	res.json(journal.filter((entry) => matchesRequest(req.body, entry)));
+});
+
+server.post('/__admin/requests/count', (req, res) => {
+	res.json({count: journal.filter((entry) => matchesRequest(req.body, entry)).length});
+});
+
+server.post('/__admin/requests/verify', (req, res) => {
+	const count = journal.filter((entry) => matchesRequest(req.body, entry)).length;
+	const expected = req.body.count;
+	if (expected === undefined ? count > 0 : count === expected) {
+		res.json({count});
+		return;
+	}
+	const message = expected === undefined ?
+		'expected at least one request matching ' + describeMatcher(req.body) + ', got 0' :
+		'expected ' + expected + (expected === 1 ? ' request' : ' requests') + ' matching ' + describeMatcher(req.body) + ', got ' + count;
+	res.status(422).json({message, count});
+});
+
+server.get('/__admin/requests/export', (req, res) => {
+	const format = req.query.format || 'json';
+	if (format !== 'json' && format !== 'har') {
+		res.status(400).json({message: "invalid format '" + format + "', use json or har"});
+		return;
+	}
+	res.type('application/json').send(JSON.stringify(format === 'har' ? harLog(journal) : journal, undefined, 2));
+});
+
+server.delete('/__admin/requests', (req, res) => {
+	journal.length = 0;
+	res.status(204).end();
+});
+
+let chaosOverride = null;
+
+function applyChaos(chaos, req, res, next) {
+	const delayMin = chaos.delayMin || 0;
+	const delay = delayMin + Math.floor(Math.random() * ((chaos.delayMax || 0) - delayMin + 1));
+	setTimeout(() => {
+		if (Math.random() < (chaos.resetRate || 0)) {
+			if (req.socket.resetAndDestroy) {
+				req.socket.resetAndDestroy();
+			} else {
+				req.socket.destroy();
+			}
+			return;
+		}
+		if (Math.random() < (chaos.failureRate || 0)) {
+			const failureCodes = chaos.failureCodes && chaos.failureCodes.length > 0 ? chaos.failureCodes : [500, 502, 503, 504, 429];
+			const failureCode = failureCodes[Math.floor(Math.random() * failureCodes.length)];
+			if (failureCode === 429) {
+				res.set('Retry-After', '1');
+			}
+			res.status(failureCode).json({message: 'injected failure'});
+			return;
+		}
+		if (Math.random() < (chaos.truncateRate || 0)) {
+			res.end = (chunk, encoding) => {
+				const body = Buffer.isBuffer(chunk) ? chunk : Buffer.from(chunk || '', typeof encoding === 'string' ? encoding : 'utf8');
+				if (!res.headersSent) {
+					res.setHeader('Content-Length', body.length);
+				}
+				res.write(body.subarray(0, Math.floor(body.length / 2)), () => res.socket.destroy());
+			};
+		}
+		next();
+	}, delay);
+}
+
+function routeChaos(chaos) {
+	return (req, res, next) => (chaosOverride ? next() : applyChaos(chaos, req, res, next));
+}
+
+server.get('/__admin/chaos', (req, res) => {
+	res.json(chaosOverride);
+});
+
+server.put('/__admin/chaos', (req, res) => {
+	chaosOverride = req.body;
+	res.json(chaosOverride);
+});
+
+server.delete('/__admin/chaos', (req, res) => {
+	chaosOverride = null;
+	res.status(204).end();
+});
+
+server.use((req, res, next) => (chaosOverride && !req.path.startsWith('/__admin') ? applyChaos(chaosOverride, req, res, next) : next()));
+
+server.use((req, res, next) => {
+	const route = req.path.startsWith('/__admin') ? undefined : matchRoute(req.method, req.path);
+	const index = route ? overrides.findIndex((override) => override.method === route.method && override.path === route.path) : -1;
+	if (route && route.validation && (index >= 0 || route.operationId in scenarios) && !validateRequest(req, res, route.validation, routeParams(route, req.path))) {
+		return;
+	}
+	let override = index < 0 ? undefined : Object.assign({}, overrides[index]);
+	if (override && override.times > 0) {
+		overrides[index].times -= 1;
+		if (overrides[index].times === 0) {
+			overrides.splice(index, 1);
+		}
+	}
+	if (override === undefined && route) {
+		override = takeScenarioResponse(route.operationId);
+	}
+	if (override === undefined) {
+		next();
+		return;
+	}
+	const respond = () => {
+		res.status(override.status || Number(route.responseCode) || 200).set(override.headers || {});
+		if ('body' in override) {
+			res.json(override.body);
+		} else {
+			res.end();
+		}
+	};
+	if (route.chaos && !chaosOverride) {
+		applyChaos(route.chaos, req, res, respond);
+	} else {
+		respond();
+	}
+});
+
 server.use(jsonServer.rewriter({
 	"/auth/register": "/auth-register",
 	"/auth/login": "/auth-login",
@@ -78,7 +722,7 @@ server.post('/checkout', (req, res) => {
 					}
 		],
 		"status": "",
-		"total_amount": null
+		"total_amount": 0
 };
 	checkWriteToDb();
 	res.status(statusCode).json(responseBody);
@@ -114,7 +758,7 @@ server.get('/orders', (req, res) => {
 				"id": "",
 				"items": [],
 				"status": "",
-				"total_amount": null
+				"total_amount": 0
 			}
 ];
 	
@@ -134,7 +778,7 @@ server.get('/orders/:orderId', (req, res) => {
 					}
 		],
 		"status": "",
-		"total_amount": null
+		"total_amount": 0
 };
 	
 	res.status(statusCode).json(responseBody);
@@ -168,7 +812,7 @@ server.get('/products', (req, res) => {
 				"id": "",
 				"image_url": "",
 				"name": "",
-				"price": null,
+				"price": 0,
 				"stock": 0,
 				"updated_at": ""
 			}
@@ -187,7 +831,7 @@ server.get('/products/:id', (req, res) => {
 		"id": "",
 		"image_url": "",
 		"name": "",
-		"price": null,
+		"price": 0,
 		"stock": 0,
 		"updated_at": ""
 	};
@@ -0,0 +1,43 @@
+package genmock
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+type TestServer struct {
+	*httptest.Server
+	Mock *Server
+	t    testing.TB
+}
+
+func NewTestServer(t testing.TB, specFilename string, opts GenerateOptions) *TestServer {
+	t.Helper()
+
+	featureFileDataStructure, err := SpecToRequestStructureMap(specFilename, opts)
+	if err != nil {
+		t.Fatalf("cannot parse spec file '%s': %v", specFilename, err)
+	}
+
+	mock := NewServer(featureFileDataStructure)
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+
+	return &TestServer{
+		Server: server,
+		Mock:   mock,
+		t:      t,
+	}
+}
+
+func (ts *TestServer) Requests() []RecordedRequest {
+	return ts.Mock.Requests()
+}
+
+func (ts *TestServer) AssertRequests(matcher RequestMatcher, count int) {
+	ts.t.Helper()
+
+	if err := ts.Mock.VerifyRequests(matcher, count); err != nil {
+		ts.t.Error(err)
+	}
+}
@@ -0,0 +1,118 @@
+package genmock
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func Test_NewTestServer_ServesSpecAndRecordsRequests(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server := NewTestServer(t, "./testdata/examplev3.yaml", GenerateOptions{MaxRecursionDepth: 1})
+
+	// Act
+	getResponse, getErr := http.Get(server.URL + "/orders/42")
+	postResponse, postErr := http.Post(server.URL+"/addresses?validate=true", "application/json", strings.NewReader(`{"city":"Ghent"}`))
+
+	// Assert
+	require.NoError(t, getErr)
+	require.NoError(t, postErr)
+	defer func() {
+		_ = getResponse.Body.Close()
+		_ = postResponse.Body.Close()
+	}()
+	assert.Equal(t, http.StatusOK, getResponse.StatusCode)
+	assert.Equal(t, http.StatusCreated, postResponse.StatusCode)
+	host := strings.TrimPrefix(server.URL, "http://")
+	requests := server.Requests()
+	for i := range requests {
+		assert.False(t, requests[i].Timestamp.IsZero())
+		requests[i].Timestamp = time.Time{}
+	}
+	assert.Equal(t, []RecordedRequest{
+		{
+			Method:  http.MethodGet,
+			Path:    "/orders/42",
+			Query:   "",
+			Url:     server.URL + "/orders/42",
+			Params:  map[string]string{"orderId": "42"},
+			Headers: map[string]string{"accept-encoding": "gzip", "host": host, "user-agent": "Go-http-client/1.1"},
+			Body:    "",
+			Route:   "GET /orders/:orderId",
+			Status:  http.StatusOK,
+		},
+		{
+			Method:  http.MethodPost,
+			Path:    "/addresses",
+			Query:   "validate=true",
+			Url:     server.URL + "/addresses?validate=true",
+			Headers: map[string]string{"accept-encoding": "gzip", "content-length": "16", "content-type": "application/json", "host": host, "user-agent": "Go-http-client/1.1"},
+			Body:    `{"city":"Ghent"}`,
+			Route:   "POST /addresses",
+			Status:  http.StatusCreated,
+		},
+	}, requests)
+}
+
+func Test_NewTestServer_ParsesV2Spec(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server := NewTestServer(t, "./testdata/examplev2.yaml", GenerateOptions{SpecMajorVersion: 2, MaxRecursionDepth: 1})
+
+	// Act
+	response, err := http.Get(server.URL + "/v1/products/abc")
+
+	// Assert
+	require.NoError(t, err)
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Len(t, server.Requests(), 1)
+}
+
+func Test_TestServer_AssertRequests(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	server := NewTestServer(t, "./testdata/examplev3.yaml", GenerateOptions{MaxRecursionDepth: 1})
+
+	// Act
+	response, err := http.Post(server.URL+"/addresses", "application/json", strings.NewReader(`{"city":"Ghent","country":"BE"}`))
+
+	// Assert
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	server.AssertRequests(RequestMatcher{Method: http.MethodPost, Path: "/addresses", Body: map[string]any{"city": "Ghent"}}, 1)
+	server.AssertRequests(RequestMatcher{Method: http.MethodGet}, 0)
+}
+
+func Test_NewTestServer_ReadsSpecOutsideWorkingDirectory(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	specFilename := filepath.Join(t.TempDir(), "openapi.yaml")
+	spec, err := os.ReadFile("./testdata/examplev3.yaml")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(specFilename, spec, 0o600))
+	server := NewTestServer(t, specFilename, GenerateOptions{MaxRecursionDepth: 1})
+
+	// Act
+	response, err := http.Get(server.URL + "/orders/42")
+
+	// Assert
+	require.NoError(t, err)
+	defer func() {
+		_ = response.Body.Close()
+	}()
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+}
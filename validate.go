@@ -0,0 +1,480 @@
+package genmock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/pb33f/libopenapi/datamodel/high/base"
+	v2high "github.com/pb33f/libopenapi/datamodel/high/v2"
+	v3high "github.com/pb33f/libopenapi/datamodel/high/v3"
+	"go.yaml.in/yaml/v4"
+)
+
+const maxValidationRecursion = 3
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type RequestValidation struct {
+	Parameters   []ParameterRule `json:"parameters,omitempty"`
+	Body         *SchemaRule     `json:"body,omitempty"`
+	BodyRequired bool            `json:"bodyRequired,omitempty"`
+}
+
+type ParameterRule struct {
+	Name     string      `json:"name"`
+	In       string      `json:"in"`
+	Required bool        `json:"required,omitempty"`
+	Schema   *SchemaRule `json:"schema,omitempty"`
+}
+
+type SchemaRule struct {
+	Type                 []string               `json:"type,omitempty"`
+	Nullable             bool                   `json:"nullable,omitempty"`
+	Enum                 []any                  `json:"enum,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	MinLength            *int64                 `json:"minLength,omitempty"`
+	MaxLength            *int64                 `json:"maxLength,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	MinItems             *int64                 `json:"minItems,omitempty"`
+	MaxItems             *int64                 `json:"maxItems,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Properties           map[string]*SchemaRule `json:"properties,omitempty"`
+	AdditionalProperties *bool                  `json:"additionalProperties,omitempty"`
+	Items                *SchemaRule            `json:"items,omitempty"`
+	AllOf                []*SchemaRule          `json:"allOf,omitempty"`
+	AnyOf                []*SchemaRule          `json:"anyOf,omitempty"`
+	OneOf                []*SchemaRule          `json:"oneOf,omitempty"`
+}
+
+type ValidationError struct {
+	Pointer string `json:"pointer"`
+	Message string `json:"message"`
+}
+
+func schemaRule(schemaProxy *base.SchemaProxy, refs []string) *SchemaRule {
+	if schemaProxy == nil {
+		return nil
+	}
+	if schemaProxy.IsReference() {
+		recursion := 0
+		for _, ref := range refs {
+			if ref == schemaProxy.GetReference() {
+				recursion++
+			}
+		}
+		if recursion >= maxValidationRecursion {
+			return &SchemaRule{}
+		}
+		refs = append(refs, schemaProxy.GetReference())
+	}
+	schema := schemaProxy.Schema()
+	if schema == nil {
+		return nil
+	}
+
+	rule := &SchemaRule{
+		Format:    schema.Format,
+		Pattern:   schema.Pattern,
+		MinLength: schema.MinLength,
+		MaxLength: schema.MaxLength,
+		Minimum:   schema.Minimum,
+		Maximum:   schema.Maximum,
+		MinItems:  schema.MinItems,
+		MaxItems:  schema.MaxItems,
+		Required:  schema.Required,
+		Nullable:  schema.Nullable != nil && *schema.Nullable,
+		Enum:      enumValues(schema.Enum),
+	}
+	for _, typeName := range schema.Type {
+		if typeName == "null" {
+			rule.Nullable = true
+			continue
+		}
+		rule.Type = append(rule.Type, typeName)
+	}
+	for key, propertySchema := range schema.Properties.FromOldest() {
+		if rule.Properties == nil {
+			rule.Properties = map[string]*SchemaRule{}
+		}
+		rule.Properties[key] = schemaRule(propertySchema, refs)
+	}
+	if schema.AdditionalProperties != nil && schema.AdditionalProperties.IsB() && !schema.AdditionalProperties.B {
+		rule.AdditionalProperties = &schema.AdditionalProperties.B
+	}
+	if schema.Items != nil && schema.Items.IsA() {
+		rule.Items = schemaRule(schema.Items.A, refs)
+	}
+	for _, allOfSchema := range schema.AllOf {
+		rule.AllOf = append(rule.AllOf, schemaRule(allOfSchema, refs))
+	}
+	for _, anyOfSchema := range schema.AnyOf {
+		rule.AnyOf = append(rule.AnyOf, schemaRule(anyOfSchema, refs))
+	}
+	for _, oneOfSchema := range schema.OneOf {
+		rule.OneOf = append(rule.OneOf, schemaRule(oneOfSchema, refs))
+	}
+
+	return rule
+}
+
+func enumValues(enum []*yaml.Node) []any {
+	var values []any
+	for _, node := range enum {
+		values = append(values, nodeValue(node))
+	}
+
+	return values
+}
+
+func parameterRuleV2(parameter *v2high.Parameter) *SchemaRule {
+	if parameter.Type == "" || parameter.Type == "file" {
+		return nil
+	}
+	rule := &SchemaRule{
+		Type:    []string{parameter.Type},
+		Format:  parameter.Format,
+		Pattern: parameter.Pattern,
+		Enum:    enumValues(parameter.Enum),
+	}
+	if parameter.MinLength != nil {
+		rule.MinLength = new(int64(*parameter.MinLength))
+	}
+	if parameter.MaxLength != nil {
+		rule.MaxLength = new(int64(*parameter.MaxLength))
+	}
+	if parameter.Minimum != nil {
+		rule.Minimum = new(float64(*parameter.Minimum))
+	}
+	if parameter.Maximum != nil {
+		rule.Maximum = new(float64(*parameter.Maximum))
+	}
+	if parameter.Items != nil && parameter.Items.Type != "" {
+		rule.Items = &SchemaRule{
+			Type:    []string{parameter.Items.Type},
+			Format:  parameter.Items.Format,
+			Pattern: parameter.Items.Pattern,
+			Enum:    enumValues(parameter.Items.Enum),
+		}
+	}
+
+	return rule
+}
+
+func parameterName(parameterIn string, name string) string {
+	if parameterIn == "path" {
+		return strings.ReplaceAll(name, "-", "")
+	}
+
+	return name
+}
+
+func requestValidationV2(pathItem *v2high.PathItem, operation *v2high.Operation) *RequestValidation {
+	validation := &RequestValidation{}
+	for _, parameter := range slices.Concat(pathItem.Parameters, operation.Parameters) {
+		required := parameter.Required != nil && *parameter.Required
+		if parameter.In == "body" {
+			validation.Body = schemaRule(parameter.Schema, nil)
+			validation.BodyRequired = required
+			continue
+		}
+		if parameter.In == "formData" {
+			continue
+		}
+		validation.Parameters = append(validation.Parameters, ParameterRule{
+			Name:     parameterName(parameter.In, parameter.Name),
+			In:       parameter.In,
+			Required: required,
+			Schema:   parameterRuleV2(parameter),
+		})
+	}
+
+	return validation
+}
+
+func requestValidationV3(pathItem *v3high.PathItem, operation *v3high.Operation) *RequestValidation {
+	validation := &RequestValidation{}
+	for _, parameter := range slices.Concat(pathItem.Parameters, operation.Parameters) {
+		validation.Parameters = append(validation.Parameters, ParameterRule{
+			Name:     parameterName(parameter.In, parameter.Name),
+			In:       parameter.In,
+			Required: parameter.Required != nil && *parameter.Required,
+			Schema:   schemaRule(parameter.Schema, nil),
+		})
+	}
+	if operation.RequestBody != nil {
+		validation.BodyRequired = operation.RequestBody.Required != nil && *operation.RequestBody.Required
+		for mediaTypeName, mediaType := range operation.RequestBody.Content.FromOldest() {
+			if strings.Contains(mediaTypeName, "json") {
+				validation.Body = schemaRule(mediaType.Schema, nil)
+				break
+			}
+		}
+	}
+
+	return validation
+}
+
+func validateRequest(request RequestStructure, r *http.Request, pathParams []string) (int, []ValidationError) {
+	if request.Validation == nil {
+		return http.StatusOK, nil
+	}
+	pathValues := map[string]string{}
+	for i, param := range request.RequestParams {
+		if i < len(pathParams) {
+			pathValues[param] = pathParams[i]
+		}
+	}
+
+	return request.Validation.validate(r, pathValues)
+}
+
+func (validation *RequestValidation) validate(r *http.Request, pathValues map[string]string) (int, []ValidationError) {
+	validationErrors := []ValidationError{}
+	for _, parameter := range validation.Parameters {
+		pointer := fmt.Sprintf("/%s/%s", parameter.In, jsonPointerToken(parameter.Name))
+		values := []string{}
+		switch parameter.In {
+		case "path":
+			if value, ok := pathValues[parameter.Name]; ok {
+				values = append(values, value)
+			}
+		case "query":
+			values = r.URL.Query()[parameter.Name]
+		case "header":
+			values = r.Header.Values(parameter.Name)
+		case "cookie":
+			if cookie, err := r.Cookie(parameter.Name); err == nil {
+				values = append(values, cookie.Value)
+			}
+		}
+		if len(values) == 0 {
+			if parameter.Required {
+				validationErrors = append(validationErrors, ValidationError{Pointer: pointer, Message: "is required"})
+			}
+			continue
+		}
+		validationErrors = append(validationErrors, parameter.Schema.validate(parameterValue(parameter.Schema, values), pointer)...)
+	}
+	if len(validationErrors) > 0 {
+		return http.StatusBadRequest, validationErrors
+	}
+	if validation.Body == nil && !validation.BodyRequired {
+		return http.StatusOK, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return http.StatusBadRequest, []ValidationError{{Pointer: "/body", Message: err.Error()}}
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if len(bytes.TrimSpace(body)) == 0 {
+		if validation.BodyRequired {
+			return http.StatusBadRequest, []ValidationError{{Pointer: "/body", Message: "is required"}}
+		}
+		return http.StatusOK, nil
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return http.StatusBadRequest, []ValidationError{{Pointer: "/body", Message: fmt.Sprintf("must be valid json: %v", err)}}
+	}
+	validationErrors = validation.Body.validate(value, "/body")
+	if len(validationErrors) > 0 {
+		return http.StatusUnprocessableEntity, validationErrors
+	}
+
+	return http.StatusOK, nil
+}
+
+func parameterValue(rule *SchemaRule, values []string) any {
+	if rule == nil {
+		return values[0]
+	}
+	if slices.Contains(rule.Type, "array") {
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		items := []any{}
+		for _, value := range values {
+			items = append(items, parameterValue(rule.Items, []string{value}))
+		}
+		return items
+	}
+	switch {
+	case slices.Contains(rule.Type, "integer"), slices.Contains(rule.Type, "number"):
+		if number, err := strconv.ParseFloat(strings.TrimSpace(values[0]), 64); err == nil {
+			return number
+		}
+	case slices.Contains(rule.Type, "boolean"):
+		if values[0] == "true" || values[0] == "false" {
+			return values[0] == "true"
+		}
+	}
+
+	return values[0]
+}
+
+func jsonPointerToken(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func jsonType(value any) string {
+	switch typedValue := value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if typedValue == float64(int64(typedValue)) {
+			return "integer"
+		}
+		return "number"
+	case int, int64:
+		return "integer"
+	}
+
+	return "null"
+}
+
+func jsonEqual(a any, b any) bool {
+	aJson, aErr := json.Marshal(a)
+	bJson, bErr := json.Marshal(b)
+
+	return aErr == nil && bErr == nil && bytes.Equal(aJson, bJson)
+}
+
+func validFormat(format string, value string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(value)
+	case "email":
+		_, err := mail.ParseAddress(value)
+		return err == nil
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() == nil
+	case "uri":
+		parsedUrl, err := url.Parse(value)
+		return err == nil && parsedUrl.Scheme != ""
+	}
+
+	return true
+}
+
+func (rule *SchemaRule) validate(value any, pointer string) []ValidationError {
+	if rule == nil {
+		return nil
+	}
+	valueType := jsonType(value)
+	if valueType == "null" {
+		if rule.Nullable || len(rule.Type) == 0 {
+			return nil
+		}
+		return []ValidationError{{Pointer: pointer, Message: "must not be null"}}
+	}
+	if len(rule.Type) > 0 && !slices.Contains(rule.Type, valueType) && (valueType != "integer" || !slices.Contains(rule.Type, "number")) {
+		return []ValidationError{{Pointer: pointer, Message: fmt.Sprintf("must be of type %s", strings.Join(rule.Type, " or "))}}
+	}
+
+	validationErrors := []ValidationError{}
+	fail := func(pointer string, format string, args ...any) {
+		validationErrors = append(validationErrors, ValidationError{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+	}
+	if len(rule.Enum) > 0 && !slices.ContainsFunc(rule.Enum, func(enumValue any) bool { return jsonEqual(enumValue, value) }) {
+		enumJson, _ := json.Marshal(rule.Enum)
+		fail(pointer, "must be one of %s", enumJson)
+	}
+	switch typedValue := value.(type) {
+	case string:
+		length := int64(utf8.RuneCountInString(typedValue))
+		if rule.MinLength != nil && length < *rule.MinLength {
+			fail(pointer, "must be at least %d characters long", *rule.MinLength)
+		}
+		if rule.MaxLength != nil && length > *rule.MaxLength {
+			fail(pointer, "must be at most %d characters long", *rule.MaxLength)
+		}
+		if pattern, err := regexp.Compile(rule.Pattern); rule.Pattern != "" && err == nil && !pattern.MatchString(typedValue) {
+			fail(pointer, "must match pattern '%s'", rule.Pattern)
+		}
+		if !validFormat(rule.Format, typedValue) {
+			fail(pointer, "must be a valid %s", rule.Format)
+		}
+	case float64:
+		if rule.Minimum != nil && typedValue < *rule.Minimum {
+			fail(pointer, "must be greater than or equal to %v", *rule.Minimum)
+		}
+		if rule.Maximum != nil && typedValue > *rule.Maximum {
+			fail(pointer, "must be less than or equal to %v", *rule.Maximum)
+		}
+	case []any:
+		if rule.MinItems != nil && int64(len(typedValue)) < *rule.MinItems {
+			fail(pointer, "must contain at least %d items", *rule.MinItems)
+		}
+		if rule.MaxItems != nil && int64(len(typedValue)) > *rule.MaxItems {
+			fail(pointer, "must contain at most %d items", *rule.MaxItems)
+		}
+		for i, item := range typedValue {
+			validationErrors = append(validationErrors, rule.Items.validate(item, fmt.Sprintf("%s/%d", pointer, i))...)
+		}
+	case map[string]any:
+		for _, key := range rule.Required {
+			if _, ok := typedValue[key]; !ok {
+				fail(fmt.Sprintf("%s/%s", pointer, jsonPointerToken(key)), "is required")
+			}
+		}
+		for _, key := range slices.Sorted(maps.Keys(typedValue)) {
+			propertyRule, ok := rule.Properties[key]
+			if !ok && rule.AdditionalProperties != nil {
+				fail(fmt.Sprintf("%s/%s", pointer, jsonPointerToken(key)), "is not allowed")
+				continue
+			}
+			validationErrors = append(validationErrors, propertyRule.validate(typedValue[key], fmt.Sprintf("%s/%s", pointer, jsonPointerToken(key)))...)
+		}
+	}
+	for _, allOfRule := range rule.AllOf {
+		validationErrors = append(validationErrors, allOfRule.validate(value, pointer)...)
+	}
+	if len(rule.AnyOf) > 0 && !slices.ContainsFunc(rule.AnyOf, func(anyOfRule *SchemaRule) bool { return len(anyOfRule.validate(value, pointer)) == 0 }) {
+		fail(pointer, "must match one of the allowed schemas")
+	}
+	if len(rule.OneOf) > 0 {
+		matches := 0
+		for _, oneOfRule := range rule.OneOf {
+			if len(oneOfRule.validate(value, pointer)) == 0 {
+				matches++
+			}
+		}
+		if matches != 1 {
+			fail(pointer, "must match exactly one of the allowed schemas")
+		}
+	}
+
+	return validationErrors
+}
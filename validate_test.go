@@ -0,0 +1,235 @@
+package genmock
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/test-go/testify/require"
+)
+
+func Test_Server_ServeHTTP_ValidatesRequest(t *testing.T) {
+	t.Parallel()
+
+	validRequestId := "7b5f6f5e-2f7a-4a53-8f4c-0e6a4b1c9d10"
+	tests := map[string]struct {
+		specFilename   string
+		method         string
+		path           string
+		headers        map[string]string
+		body           string
+		expectedCode   int
+		expectedErrors []ValidationError
+	}{
+		"valid query": {
+			specFilename: "./testdata/examplevalidation.yaml",
+			method:       http.MethodGet,
+			path:         "/pets?limit=10&tags=dog,cat",
+			expectedCode: http.StatusOK,
+		},
+		"missing required query": {
+			specFilename:   "./testdata/examplevalidation.yaml",
+			method:         http.MethodGet,
+			path:           "/pets",
+			expectedCode:   http.StatusBadRequest,
+			expectedErrors: []ValidationError{{Pointer: "/query/limit", Message: "is required"}},
+		},
+		"invalid query values": {
+			specFilename: "./testdata/examplevalidation.yaml",
+			method:       http.MethodGet,
+			path:         "/pets?limit=500&tags=dog,bird",
+			expectedCode: http.StatusBadRequest,
+			expectedErrors: []ValidationError{
+				{Pointer: "/query/limit", Message: "must be less than or equal to 100"},
+				{Pointer: "/query/tags/1", Message: `must be one of ["dog","cat"]`},
+			},
+		},
+		"invalid path and header": {
+			specFilename: "./testdata/examplevalidation.yaml",
+			method:       http.MethodPut,
+			path:         "/pets/abc",
+			headers:      map[string]string{"X-Request-Id": "not-a-uuid"},
+			body:         `{"name":"Rex"}`,
+			expectedCode: http.StatusBadRequest,
+			expectedErrors: []ValidationError{
+				{Pointer: "/path/petid", Message: "must be of type integer"},
+				{Pointer: "/header/X-Request-Id", Message: "must be a valid uuid"},
+			},
+		},
+		"missing required body": {
+			specFilename:   "./testdata/examplevalidation.yaml",
+			method:         http.MethodPut,
+			path:           "/pets/1",
+			headers:        map[string]string{"X-Request-Id": validRequestId},
+			expectedCode:   http.StatusBadRequest,
+			expectedErrors: []ValidationError{{Pointer: "/body", Message: "is required"}},
+		},
+		"invalid body": {
+			specFilename: "./testdata/examplevalidation.yaml",
+			method:       http.MethodPut,
+			path:         "/pets/1",
+			headers:      map[string]string{"X-Request-Id": validRequestId},
+			body:         `{"tag":"bird","owner":{"email":"nobody"},"toys":["ball","rope",3],"parent":{"name":1},"color":"red"}`,
+			expectedCode: http.StatusUnprocessableEntity,
+			expectedErrors: []ValidationError{
+				{Pointer: "/body/name", Message: "is required"},
+				{Pointer: "/body/color", Message: "is not allowed"},
+				{Pointer: "/body/owner/email", Message: "must be a valid email"},
+				{Pointer: "/body/parent/name", Message: "must be of type string"},
+				{Pointer: "/body/tag", Message: `must be one of ["dog","cat"]`},
+				{Pointer: "/body/toys", Message: "must contain at most 2 items"},
+				{Pointer: "/body/toys/2", Message: "must be of type string"},
+			},
+		},
+		"valid body": {
+			specFilename: "./testdata/examplevalidation.yaml",
+			method:       http.MethodPut,
+			path:         "/pets/1",
+			headers:      map[string]string{"X-Request-Id": validRequestId},
+			body:         `{"name":"Rex","tag":"dog","toys":["ball"]}`,
+			expectedCode: http.StatusOK,
+		},
+		"body matching several oneOf branches": {
+			specFilename:   "./testdata/examplevalidation.yaml",
+			method:         http.MethodPut,
+			path:           "/pets/1",
+			headers:        map[string]string{"X-Request-Id": validRequestId},
+			body:           `{"name":"Rex","weight":3}`,
+			expectedCode:   http.StatusUnprocessableEntity,
+			expectedErrors: []ValidationError{{Pointer: "/body/weight", Message: "must match exactly one of the allowed schemas"}},
+		},
+		"body matching one oneOf branch": {
+			specFilename: "./testdata/examplevalidation.yaml",
+			method:       http.MethodPut,
+			path:         "/pets/1",
+			headers:      map[string]string{"X-Request-Id": validRequestId},
+			body:         `{"name":"Rex","weight":3.5}`,
+			expectedCode: http.StatusOK,
+		},
+		"v2 invalid query": {
+			specFilename:   "./testdata/examplevalidationv2.yaml",
+			method:         http.MethodGet,
+			path:           "/pets?limit=ten",
+			expectedCode:   http.StatusBadRequest,
+			expectedErrors: []ValidationError{{Pointer: "/query/limit", Message: "must be of type integer"}},
+		},
+		"v2 invalid body": {
+			specFilename:   "./testdata/examplevalidationv2.yaml",
+			method:         http.MethodPost,
+			path:           "/pets",
+			body:           `{"tag":"dog"}`,
+			expectedCode:   http.StatusUnprocessableEntity,
+			expectedErrors: []ValidationError{{Pointer: "/body/name", Message: "is required"}},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			featureFileDataStructure, err := SpecToRequestStructureMap(data.specFilename, GenerateOptions{MaxRecursionDepth: 1, Validate: true})
+			require.NoError(t, err)
+			server := NewServer(featureFileDataStructure)
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(data.method, data.path, strings.NewReader(data.body))
+			for key, value := range data.headers {
+				request.Header.Set(key, value)
+			}
+
+			// Act
+			server.ServeHTTP(recorder, request)
+
+			// Assert
+			assert.Equal(t, data.expectedCode, recorder.Code)
+			if data.expectedErrors != nil {
+				var body struct {
+					Errors []ValidationError `json:"errors"`
+				}
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, data.expectedErrors, body.Errors)
+			}
+		})
+	}
+}
+
+func Test_Server_ServeHTTP_ValidatesBeforeOverrides(t *testing.T) {
+	t.Parallel()
+
+	validRequestId := "7b5f6f5e-2f7a-4a53-8f4c-0e6a4b1c9d10"
+	tests := map[string]struct {
+		override       string
+		scenarios      []Scenario
+		expectedCodes  []int
+		expectedStates map[string]string
+	}{
+		"override": {
+			override:       `{"method": "PUT", "path": "/pets/{petid}", "status": 503, "times": 1}`,
+			expectedCodes:  []int{http.StatusUnprocessableEntity, http.StatusServiceUnavailable, http.StatusOK},
+			expectedStates: map[string]string{},
+		},
+		"scenario": {
+			scenarios: []Scenario{{
+				Name:         "replace",
+				InitialState: "pending",
+				States: map[string]ScenarioState{
+					"pending": {
+						Responses:   map[string]ScenarioResponse{"replacePet": {Status: http.StatusAccepted}},
+						Transitions: []ScenarioTransition{{On: "replacePet", To: "done"}},
+					},
+					"done": {},
+				},
+			}},
+			expectedCodes:  []int{http.StatusUnprocessableEntity, http.StatusAccepted, http.StatusOK},
+			expectedStates: map[string]string{"replace": "done"},
+		},
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			// Arrange
+			featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplevalidation.yaml", GenerateOptions{MaxRecursionDepth: 1, Validate: true, Scenarios: data.scenarios})
+			require.NoError(t, err)
+			server := NewServer(featureFileDataStructure)
+			if data.override != "" {
+				server.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/__admin/overrides", strings.NewReader(data.override)))
+			}
+			codes := []int{}
+
+			// Act
+			for _, body := range []string{`{"tag":"bird"}`, `{"name":"Rex"}`, `{"name":"Rex"}`} {
+				recorder := httptest.NewRecorder()
+				request := httptest.NewRequest(http.MethodPut, "/pets/1", strings.NewReader(body))
+				request.Header.Set("X-Request-Id", validRequestId)
+				server.ServeHTTP(recorder, request)
+				codes = append(codes, recorder.Code)
+			}
+
+			// Assert
+			assert.Equal(t, data.expectedCodes, codes)
+			assert.Equal(t, data.expectedStates, server.ScenarioStates())
+		})
+	}
+}
+
+func Test_SpecToRequestStructureMap_SkipsValidationByDefault(t *testing.T) {
+	t.Parallel()
+
+	// Arrange
+	featureFileDataStructure, err := SpecToRequestStructureMap("./testdata/examplevalidation.yaml", GenerateOptions{MaxRecursionDepth: 1})
+	require.NoError(t, err)
+	server := NewServer(featureFileDataStructure)
+	recorder := httptest.NewRecorder()
+
+	// Act
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/pets", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Nil(t, featureFileDataStructure["get"]["/pets"][0].Validation)
+}